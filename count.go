@@ -0,0 +1,42 @@
+package dsvreader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// CountRows returns the number of rows in delimiter-separated data read from r.
+//
+// Columns aren't parsed, so CountRows is much faster than reading all the rows
+// with Reader. It may be used for sizing allocations or for progress reporting
+// before the actual processing.
+//
+// sep is the column delimiter the data is encoded with. Rows are always
+// delimited by newlines, so it doesn't affect the result.
+//
+// Like Reader.Next, CountRows returns an error if the last row
+// isn't terminated by a newline.
+func CountRows(r io.Reader, sep byte) (int, error) {
+	var buf [64 << 10]byte
+	rows := 0
+	tail := false
+	for {
+		n, err := r.Read(buf[:])
+		if n > 0 {
+			b := buf[:n]
+			rows += bytes.Count(b, []byte{'\n'})
+			tail = b[n-1] != '\n'
+		}
+		if err == nil {
+			continue
+		}
+		if err != io.EOF {
+			return rows, fmt.Errorf("cannot read row #%d: %w", rows+1, err)
+		}
+		if tail {
+			return rows, fmt.Errorf("%w at the end of row #%d", ErrMissingNewline, rows+1)
+		}
+		return rows, nil
+	}
+}
@@ -2,8 +2,14 @@ package dsvreader
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"fmt"
 	"io"
+	"strconv"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
 	"unsafe"
 )
 
@@ -39,9 +45,52 @@ func NewCustom(sep byte, r io.Reader) *Reader {
 	return &tr
 }
 
+// NewCustomString returns new Reader that reads data delimited
+// by the multi-byte separator sep from r, e.g. `||` or `␟`.
+//
+// Quoting and doubled separators aren't supported for multi-byte separators,
+// so reading results in an error if they are enabled via SetQuoting
+// or SetDoubledEscape. NewCustomString panics if sep is empty.
+func NewCustomString(sep string, r io.Reader) *Reader {
+	if sep == "" {
+		panic("dsvreader: empty separator")
+	}
+	if len(sep) == 1 {
+		return NewCustom(sep[0], r)
+	}
+	var tr Reader
+	tr.sep = sep[0]
+	tr.sepStr = []byte(sep)
+	tr.updateSlowCols()
+	tr.Reset(r)
+	return &tr
+}
+
+// NewBytes returns new Reader that reads data delimited by sep from data.
+//
+// Rows are read directly from data without copying it to internal buffers.
+// Note that column readers may modify data in place while unescaping
+// or unquoting columns.
+func NewBytes(sep byte, data []byte) *Reader {
+	var tr Reader
+	tr.sep = sep
+	tr.Reset(nil)
+	tr.rb = data
+	tr.rbUnescape = (bytes.IndexByte(data, '\\') >= 0)
+	tr.rErr = io.EOF
+	return &tr
+}
+
+// NewString returns new Reader that reads data delimited by sep from s.
+//
+// s is copied once, so the Reader doesn't depend on it.
+func NewString(sep byte, s string) *Reader {
+	return NewBytes(sep, []byte(s))
+}
+
 // Reader reads delimiter-separated data.
 //
-// Call NewCSV, NewTSV, NewPSV for creating new reader.
+// Call NewCSV, NewTSV, NewPSV or NewBytes for creating new reader.
 // Call Next before reading the next row.
 //
 // It is expected that columns are separated by delimiter while rows
@@ -52,6 +101,11 @@ type Reader struct {
 	rErr error
 	rBuf [4 << 10]byte
 
+	// rBufExt is used instead of rBuf if set via SetBufferSize.
+	rBufExt []byte
+
+	ctx context.Context
+
 	col int
 	row int
 
@@ -61,24 +115,188 @@ type Reader struct {
 
 	err          error
 	sep          byte
+	sepStr       []byte
 	needUnescape bool
+
+	// slowCols is set if columns must be read via nextColSlow,
+	// see updateSlowCols.
+	slowCols bool
+
+	lines             [][]byte
+	remaining         [][]byte
+	keepTrailingEmpty bool
+	stringMaxStrict   bool
+	runeLenient       bool
+	enumFold          bool
+
+	charset CharsetDecoder
+
+	origRow    []byte
+	rowMutated bool
+	badRow     []byte
+
+	tabWidth  int
+	expandBuf []byte
+
+	header         []string
+	headerIdx      map[string]int
+	headerAnyOrder bool
+	requiredCols   []string
+	columnNames    []string
+	headerRowIdx   int
+	namedRow       int
+	namedCols      [][]byte
+	namedBuf       []byte
+
+	percentAsFraction bool
+
+	maxFieldSize int
+	maxRowSize   int
+	trimSpace    bool
+	trimCutset   string
+	trimChars    string
+
+	rbUnescape      bool
+	scratchUnescape bool
+
+	peeked       bool
+	peekBuf      []byte
+	peekUnescape bool
+	peekErr      error
+	keepBuf      []byte
+
+	comment     []byte
+	rowFilter   func(raw []byte) bool
+	skippedRows int
+	skipEmpty   bool
+
+	loc      *time.Location
+	locCache map[string]*time.Location
+
+	rawRow      []byte
+	rowExpanded bool
+	trimCR      bool
+	rowCR       bool
+	keepRawBuf  []byte
+	lineBuf     []byte
+
+	unescapeMode UnescapeMode
+	noUnescape   bool
+	unescaper    func(dst, src []byte) []byte
+	unescapeBuf  []byte
+
+	atBuf      []byte
+	peekColBuf []byte
+
+	nanTokens       []string
+	nanTokensFold   bool
+	uintAutoBase    bool
+	decimalSep      byte
+	thousandsSep    byte
+	rejectNonFinite bool
+
+	trueTokens    []string
+	falseTokens   []string
+	unknownTokens []string
+
+	maxErrorContext int
+
+	isoDurationApprox bool
+
+	missingValue []byte
+	colMissing   bool
+
+	prefetch     int
+	pf           *prefetcher
+	prefetchBufs [][]byte
+
+	strictDates bool
+	dateLayout  string
+
+	nullToken  []byte
+	nullAsZero bool
+
+	colBuf    []byte
+	base64Enc *base64.Encoding
+
+	parallelCols  int
+	parallelBuf   [][]byte
+	parallelNulls []bool
+
+	doubledEscape bool
+
+	expectedCols int
+	expectWidth  bool
+
+	quote      byte
+	quoteState quoteState
+	colQuoted  bool
+	lazyQuotes bool
 }
 
 // Reset resets the reader for reading from r.
 func (tr *Reader) Reset(r io.Reader) {
+	tr.stopPrefetch()
 	tr.r = r
 	tr.rb = nil
 	tr.rErr = nil
 
 	tr.col = 0
 	tr.row = 0
+	tr.skippedRows = 0
 
 	tr.rowBuf = nil
 	tr.b = nil
 	tr.scratch = tr.scratch[:0]
+	tr.rawRow = nil
 
 	tr.err = nil
 	tr.needUnescape = false
+	tr.colQuoted = false
+	tr.quoteState = quoteFieldStart
+	tr.rbUnescape = false
+	tr.scratchUnescape = false
+
+	tr.peeked = false
+	tr.peekBuf = nil
+	tr.peekErr = nil
+
+	tr.rowMutated = false
+	tr.badRow = tr.badRow[:0]
+
+	if tr.expectWidth {
+		tr.expectedCols = 0
+	}
+
+	tr.header = nil
+	tr.headerIdx = nil
+	tr.namedRow = 0
+	if tr.columnNames != nil {
+		tr.setHeader(tr.columnNames)
+	}
+}
+
+// SetSeparator sets the column separator, e.g. for reusing the reader
+// for data with different separators.
+//
+// It replaces the separator set by the constructor, including multi-byte
+// separators set via NewCustomString. Call it before the first Next call
+// on the data, usually right after Reset.
+func (tr *Reader) SetSeparator(sep byte) {
+	tr.sep = sep
+	tr.sepStr = nil
+	tr.updateSlowCols()
+}
+
+// ResetKeepBuffers resets the reader for reading from r while retaining
+// the memory allocated for internal buffers.
+//
+// This is the same as Reset, which never frees the buffers. Prefer it
+// over creating new readers when processing many streams, since the
+// buffers are grown only until they fit the longest row, so subsequent
+// streams are read without memory allocations.
+func (tr *Reader) ResetKeepBuffers(r io.Reader) {
+	tr.Reset(r)
 }
 
 // Error returns the last error.
@@ -94,6 +312,32 @@ func (tr *Reader) ResetError() {
 	tr.err = nil
 }
 
+// SkipToNextRow resets the current error and discards the unread columns
+// of the current row, so the following Next call reads the next row.
+//
+// This allows logging bad rows and proceeding with the rest of data:
+//
+//	for tr.Next() {
+//		n := tr.Int()
+//		if err := tr.Error(); err != nil {
+//			log.Printf("skipping bad row: %s", err)
+//			tr.SkipToNextRow()
+//			continue
+//		}
+//		process(n)
+//	}
+//
+// Read errors from the underlying reader are returned again by Next,
+// since the rest of data cannot be read after them. The end of data
+// isn't reset.
+func (tr *Reader) SkipToNextRow() {
+	if tr.err == io.EOF {
+		return
+	}
+	tr.err = nil
+	tr.b = nil
+}
+
 // HasCols returns true if the current row contains unread columns.
 //
 // An empty row doesn't contain columns.
@@ -117,55 +361,644 @@ func (tr *Reader) Next() bool {
 		return false
 	}
 	if tr.HasCols() {
-		tr.err = fmt.Errorf("row #%d %q contains unread columns: %q", tr.row, tr.rowBuf, tr.b)
+		tr.err = fmt.Errorf("row #%d %s contains unread columns: %s", tr.row, tr.quoteRow(tr.rowBuf), tr.quoteRow(tr.b))
+		return false
+	}
+
+	if err := tr.checkSepStr(); err != nil {
+		tr.err = fmt.Errorf("cannot read row #%d: %w", tr.row+1, err)
 		return false
 	}
 
 	tr.row++
 	tr.col = 0
 	tr.rowBuf = nil
+	tr.rawRow = nil
+	tr.rowMutated = false
+	tr.unescapeBuf = tr.unescapeBuf[:0]
+
+	var b []byte
+	var err error
+	if tr.peeked {
+		b, tr.needUnescape, err = tr.peekBuf, tr.peekUnescape, tr.peekErr
+		tr.peeked = false
+		tr.peekBuf = nil
+		tr.peekErr = nil
+	} else {
+		b, tr.needUnescape, err = tr.readRow(tr.row)
+	}
+	if err != nil {
+		if err == io.EOF {
+			// There is no row to load, so Row keeps returning the last row.
+			tr.row--
+		}
+		tr.err = err
+		return false
+	}
+
+	tr.rowCR = false
+	if tr.trimCR && len(b) > 0 && b[len(b)-1] == '\r' {
+		b = b[:len(b)-1]
+		tr.rowCR = true
+	}
+
+	tr.rawRow = b
+	tr.rowExpanded = false
+	if tr.tabWidth > 0 && bytes.IndexByte(b, '\t') >= 0 {
+		b = tr.expandTabs(b)
+		tr.rowExpanded = true
+	}
+	tr.rowBuf = b
+	tr.b = tr.rowBuf
+
+	if tr.expectedCols > 0 || tr.expectWidth {
+		return tr.checkWidth()
+	}
+	return true
+}
+
+// Skip advances past the next n rows without reading their columns,
+// e.g. for skipping preamble lines before the header.
+//
+// The skipped rows aren't checked against SetExpectedCols and are counted
+// in row numbers. Like Next, Skip must be called after reading all
+// the columns on the current row. Skip returns false if there are less
+// than n rows or on error. Check Error after Skip returns false.
+func (tr *Reader) Skip(n int) bool {
+	expectedCols, expectWidth := tr.expectedCols, tr.expectWidth
+	tr.expectedCols, tr.expectWidth = 0, false
+	defer func() {
+		tr.expectedCols, tr.expectWidth = expectedCols, expectWidth
+	}()
+
+	for i := 0; i < n; i++ {
+		if !tr.Next() {
+			return false
+		}
+		// Discard the row.
+		tr.b = nil
+	}
+	return tr.err == nil
+}
+
+// ForEach calls fn for every remaining row.
+//
+// fn is called after advancing to the row and must read all its columns,
+// as required by Next. Iteration stops at the end of data or on the first
+// error either returned by fn or set on the reader. The error is returned.
+func (tr *Reader) ForEach(fn func(tr *Reader) error) error {
+	for tr.Next() {
+		if err := fn(tr); err != nil {
+			return err
+		}
+	}
+	return tr.Error()
+}
+
+// SetExpectedCols sets the number of columns every row must contain.
+//
+// Next returns false with an error on rows with other number
+// of columns. Empty rows contain no columns. Pass 0 for allowing
+// rows with variable number of columns. This is the default.
+func (tr *Reader) SetExpectedCols(n int) {
+	tr.expectedCols = n
+	tr.expectWidth = false
+}
+
+// ExpectHeaderWidth makes Next verify that every row contains the same
+// number of columns as the first row read after the call or after Reset,
+// which is usually the header.
+//
+// Next returns false with an error on rows with other number of columns.
+// Call SetExpectedCols(0) for disabling the check.
+func (tr *Reader) ExpectHeaderWidth() {
+	tr.expectedCols = 0
+	tr.expectWidth = true
+}
+
+func (tr *Reader) checkWidth() bool {
+	n := tr.countCols(tr.rowBuf)
+	if tr.expectedCols == 0 {
+		// The first row sets the expected number of columns.
+		tr.expectedCols = n
+		return true
+	}
+	if n != tr.expectedCols {
+		tr.err = fmt.Errorf("row #%d %s contains %d columns; expecting %d columns", tr.row, tr.quoteRow(tr.rowBuf), n, tr.expectedCols)
+		return false
+	}
+	return true
+}
+
+// NumCols returns the total number of columns in the current row
+// including the already read columns.
+//
+// Columns aren't consumed. Quoted fields are counted as single columns,
+// see SetQuoting. Empty rows contain no columns, while trailing empty
+// columns are counted. Use Col for the number of read columns.
+func (tr *Reader) NumCols() int {
+	return tr.countCols(tr.unmutatedRow())
+}
+
+// countCols returns the number of columns in row.
+func (tr *Reader) countCols(row []byte) int {
+	if len(row) == 0 {
+		return 0
+	}
+	if tr.quote == 0 && !tr.doubledEscape {
+		// Fast path - count separators.
+		if tr.sepStr != nil {
+			return bytes.Count(row, tr.sepStr) + 1
+		}
+		return bytes.Count(row, []byte{tr.sep}) + 1
+	}
+
+	// Slow path - skip quoted fields and doubled separators.
+	n := 1
+	fieldStart := true
+	for i := 0; i < len(row); i++ {
+		c := row[i]
+		switch {
+		case fieldStart && tr.quote != 0 && c == tr.quote:
+			// Skip the quoted field up to the closing quote.
+			for i++; i < len(row); i++ {
+				if row[i] != tr.quote {
+					continue
+				}
+				if i+1 < len(row) && row[i+1] == tr.quote {
+					i++
+					continue
+				}
+				if tr.lazyQuotes && i+1 < len(row) && row[i+1] != tr.sep {
+					// Literal quote char.
+					continue
+				}
+				break
+			}
+			fieldStart = false
+		case c == tr.sep && tr.doubledEscape && i+1 < len(row) && row[i+1] == tr.sep:
+			i++
+			fieldStart = false
+		case c == tr.sep:
+			n++
+			fieldStart = true
+		default:
+			fieldStart = false
+		}
+	}
+	return n
+}
+
+// PeekRow returns the next row without advancing to it.
+//
+// The following Next call advances to the returned row. The current row
+// may be read as usual after PeekRow call.
+//
+// Returns false if there are no more rows. Check Error after Next
+// returns false in this case.
+//
+// The returned value is valid until the Next call following it.
+func (tr *Reader) PeekRow() ([]byte, bool) {
+	if tr.peeked {
+		return tr.peekBuf, tr.peekErr == nil
+	}
+	if tr.err != nil {
+		return nil, false
+	}
+
+	// Reading the next row may overwrite the buffers the current row
+	// points to, so move the current row to a separate buffer.
+	if tr.rowBuf != nil {
+		tr.keepBuf = append(tr.keepBuf[:0], tr.rowBuf...)
+		if tr.b != nil {
+			tr.b = tr.keepBuf[len(tr.rowBuf)-len(tr.b):]
+		}
+		tr.rowBuf = tr.keepBuf
+		if tr.rowExpanded {
+			tr.keepRawBuf = append(tr.keepRawBuf[:0], tr.rawRow...)
+			tr.rawRow = tr.keepRawBuf
+		} else {
+			tr.rawRow = tr.rowBuf
+		}
+	}
+
+	tr.peekBuf, tr.peekUnescape, tr.peekErr = tr.readRow(tr.row + 1)
+	tr.peeked = true
+	return tr.peekBuf, tr.peekErr == nil
+}
+
+// Underlying returns the reader for the data following the last row
+// returned by Next.
+//
+// The returned reader yields the bytes buffered by tr, including the row
+// read by PeekRow, followed by the remaining data from the reader passed
+// to Reset. This allows reading trailing non-DSV data such as footers
+// after the table. tr mustn't be used for reading after this call.
+func (tr *Reader) Underlying() io.Reader {
+	var buf []byte
+	if tr.peeked && tr.peekErr == nil {
+		buf = append(buf, tr.peekBuf...)
+		buf = append(buf, '\n')
+	}
+	buf = append(buf, tr.scratch...)
+	buf = append(buf, tr.rb...)
+
+	rErr := tr.rErr
+	if rErr == nil {
+		pending, err := tr.stopPrefetch()
+		buf = append(buf, pending...)
+		rErr = err
+	}
+
+	rs := []io.Reader{bytes.NewReader(buf)}
+	switch {
+	case rErr == nil:
+		rs = append(rs, tr.r)
+	case rErr != io.EOF:
+		rs = append(rs, &errReader{err: rErr})
+	}
+	return io.MultiReader(rs...)
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+// readRow reads the next row, skipping comment lines and rows
+// rejected by the row filter.
+//
+// row is the row number used in error messages. escaped is set to true
+// if the row may contain escape sequences.
+func (tr *Reader) readRow(row int) (b []byte, escaped bool, err error) {
+	for {
+		b, escaped, err = tr.readLine(row)
+		if err != nil {
+			return nil, false, err
+		}
+		if tr.isComment(b) || tr.skipEmpty && tr.isEmptyLine(b) || tr.rowFilter != nil && !tr.rowFilter(b) {
+			tr.skippedRows++
+			continue
+		}
+		return b, escaped, nil
+	}
+}
+
+func (tr *Reader) isComment(b []byte) bool {
+	return len(tr.comment) > 0 && bytes.HasPrefix(b, tr.comment)
+}
 
+func (tr *Reader) isEmptyLine(b []byte) bool {
+	return len(b) == 0 || tr.trimCR && len(b) == 1 && b[0] == '\r'
+}
+
+// readLine reads the next line from the underlying reader.
+func (tr *Reader) readLine(row int) (b []byte, escaped bool, err error) {
 	for {
 		if len(tr.rb) == 0 {
 			// Read buffer is empty. Attempt to fill it.
 			if tr.rErr != nil {
-				tr.err = tr.rErr
-				if tr.err != io.EOF {
-					tr.err = fmt.Errorf("cannot read row #%d: %s", tr.row, tr.err)
+				err = tr.rErr
+				if err != io.EOF {
+					err = fmt.Errorf("cannot read row #%d: %w", row, err)
+				} else if tr.quote != 0 && tr.quoteState == quoteQuoted {
+					if tr.lazyQuotes && len(tr.scratch) > 0 && tr.scratch[len(tr.scratch)-1] == '\n' {
+						// The unterminated quoted field lasts until the end of data.
+						b = tr.scratch[:len(tr.scratch)-1]
+						tr.scratch = tr.scratch[:0]
+						escaped = tr.scratchUnescape
+						tr.scratchUnescape = false
+						tr.quoteState = quoteFieldStart
+						return b, escaped, nil
+					}
+					err = fmt.Errorf("cannot find closing quote in row #%d; row: %s", row, tr.quoteRow(tr.scratch))
 				} else if len(tr.scratch) > 0 {
-					tr.err = fmt.Errorf("cannot find newline at the end of row #%d; row: %q", tr.row, tr.scratch)
+					err = fmt.Errorf("%w at the end of row #%d; row: %s", ErrMissingNewline, row, tr.quoteRow(tr.scratch))
 				}
-				return false
+				return nil, false, err
 			}
-			n, err := tr.r.Read(tr.rBuf[:])
-			tr.rb = tr.rBuf[:n]
-			tr.needUnescape = (bytes.IndexByte(tr.rb, '\\') >= 0)
+			if tr.ctx != nil {
+				if err := tr.ctx.Err(); err != nil {
+					return nil, false, fmt.Errorf("cannot read row #%d: %w", row, err)
+				}
+			}
+			rb, err := tr.readChunk()
+			tr.rb = rb
+			tr.rbUnescape = (bytes.IndexByte(tr.rb, '\\') >= 0)
 			tr.rErr = err
 		}
 
 		// Search for the end of the current row.
-		n := bytes.IndexByte(tr.rb, '\n')
+		n := tr.findRowEnd(tr.rb)
+		if tr.maxRowSize > 0 {
+			size := len(tr.scratch) + len(tr.rb)
+			if n >= 0 {
+				size = len(tr.scratch) + n
+			}
+			if size > tr.maxRowSize {
+				start := tr.rb
+				if len(tr.scratch) > 0 {
+					start = tr.scratch
+				}
+				return nil, false, fmt.Errorf("row #%d exceeds max row size %d; row: %s", row, tr.maxRowSize, tr.quoteRow(start))
+			}
+		}
 		if n >= 0 {
 			// Fast path: the row has been found.
-			b := tr.rb[:n]
+			b = tr.rb[:n]
 			tr.rb = tr.rb[n+1:]
+			escaped = tr.rbUnescape
 			if len(tr.scratch) > 0 {
 				tr.scratch = append(tr.scratch, b...)
 				b = tr.scratch
 				tr.scratch = tr.scratch[:0]
+				escaped = escaped || tr.scratchUnescape
+				tr.scratchUnescape = false
 			}
-			tr.rowBuf = b
-			tr.b = tr.rowBuf
-			return true
+			return b, escaped, nil
 		}
 
 		// Slow path: cannot find the end of row.
 		// Append tr.rb to tr.scratch and repeat.
 		tr.scratch = append(tr.scratch, tr.rb...)
+		tr.scratchUnescape = tr.scratchUnescape || tr.rbUnescape
 		tr.rb = nil
 	}
 }
 
+// SetComment sets the comment char, e.g. `#`.
+//
+// Lines starting with the char are skipped by Next and aren't counted
+// as rows in error messages. The char in other positions has no special
+// meaning. Pass 0 for disabling comments. This is the default.
+//
+// This is a shorthand for SetCommentString with a single-char prefix.
+func (tr *Reader) SetComment(c byte) {
+	if c == 0 {
+		tr.SetCommentString("")
+		return
+	}
+	tr.SetCommentString(string(c))
+}
+
+// SetSkipEmptyLines controls whether Next skips empty lines.
+//
+// Lines containing only the carriage return are empty if SetTrimCR
+// is enabled. Like comment lines, skipped lines aren't counted as rows
+// in error messages, so row numbers may differ from line numbers.
+// By default empty lines are read as rows without columns.
+func (tr *Reader) SetSkipEmptyLines(skip bool) {
+	tr.skipEmpty = skip
+}
+
+// SetCommentString sets the prefix for comment lines, e.g. `//` or `--`.
+//
+// Lines starting with the prefix are skipped by Next and aren't counted
+// as rows in error messages.
+// Pass an empty prefix for disabling comments. This is the default.
+func (tr *Reader) SetCommentString(prefix string) {
+	tr.comment = append(tr.comment[:0], prefix...)
+}
+
+// SetRowFilter sets the filter for rows.
+//
+// Next skips rows for which f returns false without splitting them
+// into columns. f receives the raw row without the terminating newline.
+// f must not hold references to the row after returning.
+//
+// Skipped rows aren't counted as rows in error messages.
+// Use SkippedRows for obtaining the number of skipped rows.
+//
+// Pass nil for disabling the filter. This is the default.
+func (tr *Reader) SetRowFilter(f func(raw []byte) bool) {
+	tr.rowFilter = f
+}
+
+// SkippedRows returns the number of rows skipped since the last Reset
+// because of comments, empty lines or the row filter.
+func (tr *Reader) SkippedRows() int {
+	return tr.skippedRows
+}
+
+// SetTabExpand enables expanding tabs to spaces in every row before
+// splitting it into columns. Tab stops are placed every width bytes.
+//
+// This is useful for reports aligned with a mix of tabs and spaces.
+// Obviously, it shouldn't be used for TSV data.
+//
+// Pass 0 for disabling tab expansion. This is the default.
+func (tr *Reader) SetTabExpand(width int) {
+	tr.tabWidth = width
+}
+
+func (tr *Reader) expandTabs(b []byte) []byte {
+	d := tr.expandBuf[:0]
+	for {
+		n := bytes.IndexByte(b, '\t')
+		if n < 0 {
+			d = append(d, b...)
+			break
+		}
+		d = append(d, b[:n]...)
+		spaces := tr.tabWidth - len(d)%tr.tabWidth
+		for i := 0; i < spaces; i++ {
+			d = append(d, ' ')
+		}
+		b = b[n+1:]
+	}
+	tr.expandBuf = d
+	return d
+}
+
+// minBufferSize is the minimum size of the read buffer.
+const minBufferSize = 64
+
+// SetBufferSize sets the size of the buffer for reading from
+// the underlying reader. Sizes below 64 bytes are rounded up to 64 bytes.
+//
+// Bigger buffers reduce the number of reads from slow readers and speed up
+// reading rows bigger than the buffer. The default size is 4KB.
+// Buffers used by SetPrefetch have the same size.
+// Pass 0 for restoring the default size.
+//
+// The behavior is undefined if the buffer size is changed after reading
+// has been started. Call it before the first Next call after Reset.
+func (tr *Reader) SetBufferSize(n int) {
+	switch {
+	case n == 0:
+		tr.rBufExt = nil
+		return
+	case n < minBufferSize:
+		n = minBufferSize
+	}
+	if cap(tr.rBufExt) >= n {
+		tr.rBufExt = tr.rBufExt[:n]
+		return
+	}
+	tr.rBufExt = make([]byte, n)
+}
+
+// readBuf returns the buffer for reading from the underlying reader.
+func (tr *Reader) readBuf() []byte {
+	if tr.rBufExt != nil {
+		return tr.rBufExt
+	}
+	return tr.rBuf[:]
+}
+
+// SetMissingColumnValue sets the value returned for columns missing
+// at the end of short rows.
+//
+// By default reading past the last column in the row results
+// in an error. Pass nil for restoring the default behavior.
+//
+// The value is returned as is without unescaping. Note that HasCols
+// still returns false for exhausted rows.
+func (tr *Reader) SetMissingColumnValue(value []byte) {
+	if value == nil {
+		tr.missingValue = nil
+	} else {
+		tr.missingValue = append([]byte{}, value...)
+	}
+	tr.updateSlowCols()
+}
+
+// SetTrimCR controls whether the carriage return is trimmed from the end
+// of rows, so files with Windows-style `\r\n` line endings may be read.
+//
+// Carriage returns in the middle of rows are preserved. Rows may have
+// mixed line endings. By default rows are split on `\n` only,
+// so the carriage return belongs to the last column.
+func (tr *Reader) SetTrimCR(trim bool) {
+	tr.trimCR = trim
+}
+
+// SetContext sets the context for reading rows.
+//
+// Next checks ctx before each read from the underlying reader and fails
+// with the context error if ctx is done. The read itself isn't interrupted,
+// so reads blocked on slow sources are cancelled only after they return.
+// Rows already buffered are still read after ctx is done.
+//
+// The context is kept across Reset calls. Pass nil for disabling
+// the checks. This is the default.
+func (tr *Reader) SetContext(ctx context.Context) {
+	tr.ctx = ctx
+}
+
+// SetMaxRowSize limits the size of a single row to n bytes
+// excluding the newline.
+//
+// Next fails when reading a bigger row instead of buffering it,
+// so malformed data without newlines cannot exhaust memory.
+// Pass 0 for unlimited row size. This is the default.
+func (tr *Reader) SetMaxRowSize(n int) {
+	tr.maxRowSize = n
+}
+
+// SetTrimSpace controls whether leading and trailing spaces and tabs
+// are trimmed from columns, e.g. ` 42 ` is read as 42 and a column
+// containing only spaces is read as empty.
+//
+// Quoted fields are never trimmed, see SetQuoting.
+// Disabled by default.
+func (tr *Reader) SetTrimSpace(trim bool) {
+	tr.trimSpace = trim
+	tr.updateTrimChars()
+	tr.updateSlowCols()
+}
+
+// SetTrimCutset sets chars, which are trimmed from both ends of columns,
+// e.g. `*` for reading `**42**` as 42.
+//
+// Unlike quoting, the chars are trimmed unconditionally and independently
+// on each end. Combined with SetTrimSpace, spaces and tabs are trimmed
+// along with the chars in any order. Quoted fields are never trimmed,
+// see SetQuoting. Pass an empty cutset for disabling trimming.
+// This is the default.
+func (tr *Reader) SetTrimCutset(cutset string) {
+	tr.trimCutset = cutset
+	tr.updateTrimChars()
+	tr.updateSlowCols()
+}
+
+// updateTrimChars updates chars trimmed from columns by nextCol.
+func (tr *Reader) updateTrimChars() {
+	tr.trimChars = ""
+	if tr.trimCutset == "" {
+		return
+	}
+	tr.trimChars = tr.trimCutset
+	if tr.trimSpace {
+		tr.trimChars += " \t"
+	}
+}
+
+// trimSpace returns b without leading and trailing spaces and tabs.
+func trimSpace(b []byte) []byte {
+	for len(b) > 0 && (b[0] == ' ' || b[0] == '\t') {
+		b = b[1:]
+	}
+	for len(b) > 0 && (b[len(b)-1] == ' ' || b[len(b)-1] == '\t') {
+		b = b[:len(b)-1]
+	}
+	return b
+}
+
+// SetMaxFieldSize limits the size of a single column to n bytes.
+//
+// Reading a bigger column results in an error.
+// Pass 0 for unlimited column size. This is the default.
+func (tr *Reader) SetMaxFieldSize(n int) {
+	tr.maxFieldSize = n
+	tr.updateSlowCols()
+}
+
+// RawRow returns the current row as it is split into columns,
+// without the terminating newline.
+//
+// The row doesn't contain the carriage return trimmed by SetTrimCR
+// and contains tabs expanded by SetTabExpand. It isn't affected
+// by in-place unescaping performed by column readers, so it stays
+// the same while the columns are read. Use RawLine for the row exactly
+// as it was read. nil is returned before the first Next call.
+//
+// The returned value is valid until the Next call.
+func (tr *Reader) RawRow() []byte {
+	if tr.rowBuf == nil {
+		return nil
+	}
+	tr.saveRow()
+	return tr.origRow
+}
+
+// RawLine returns the current row exactly as it was read, including
+// the terminating newline and the carriage return trimmed by SetTrimCR.
+//
+// The line isn't affected by tab expansion or by in-place unescaping
+// performed by column readers, so concatenating all the lines
+// reconstructs the original stream, except of skipped comment lines.
+//
+// The returned value is valid until the next call to Reader.
+func (tr *Reader) RawLine() []byte {
+	if tr.rowBuf == nil {
+		return nil
+	}
+	raw := tr.rawRow
+	if !tr.rowExpanded {
+		raw = tr.unmutatedRow()
+	}
+	tr.lineBuf = append(tr.lineBuf[:0], raw...)
+	if tr.rowCR {
+		tr.lineBuf = append(tr.lineBuf, '\r')
+	}
+	tr.lineBuf = append(tr.lineBuf, '\n')
+	return tr.lineBuf
+}
+
 // SkipCol skips the next column from the current row.
 func (tr *Reader) SkipCol() {
 	if tr.err != nil {
@@ -177,6 +1010,33 @@ func (tr *Reader) SkipCol() {
 	}
 }
 
+// PeekCol returns the next column from the current row without consuming it,
+// so the following column reader reads the same column.
+//
+// This is useful for detecting the column type before reading it.
+// The returned bytes aren't unescaped. nil is returned if the column
+// cannot be read, the error is reported by the following column reader.
+//
+// The returned value is valid until the next call to Reader.
+func (tr *Reader) PeekCol() []byte {
+	if tr.err != nil {
+		return nil
+	}
+	b, col, colMissing, colQuoted := tr.b, tr.col, tr.colMissing, tr.colQuoted
+	if tr.doubledEscape || tr.quote != 0 && len(b) > 0 && b[0] == tr.quote {
+		// Read the column from a copy, since nextCol modifies
+		// such columns in place.
+		tr.peekColBuf = append(tr.peekColBuf[:0], b...)
+		tr.b = tr.peekColBuf
+	}
+	v, err := tr.nextCol()
+	tr.b, tr.col, tr.colMissing, tr.colQuoted = b, col, colMissing, colQuoted
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
 // Bytes returns the next bytes column value from the current row.
 //
 // The returned value is valid until the next call to Reader.
@@ -189,44 +1049,70 @@ func (tr *Reader) Bytes() []byte {
 		tr.setColError("cannot read `bytes`", err)
 		return nil
 	}
+	if !tr.needUnescape && tr.unescaper == nil && tr.charset == nil {
+		// Fast path - nothing to unescape or transcode.
+		return b
+	}
+	return tr.decodeBytes(b)
+}
+
+// decodeBytes unescapes and transcodes the column b read by nextCol.
+//
+// nil is returned on error.
+func (tr *Reader) decodeBytes(b []byte) []byte {
+	b, err := tr.unescape(b)
+	if err != nil {
+		tr.setColError("cannot unescape `bytes`", err)
+		return nil
+	}
+
+	if tr.charset != nil {
+		// Slow path - transcode the column to UTF-8.
+		d, err := tr.charset.Bytes(b)
+		if err != nil {
+			tr.setColError("cannot decode `bytes`", err)
+			return nil
+		}
+		b = d
+	}
+	return b
+}
 
-	if !tr.needUnescape {
+func (tr *Reader) unescape(b []byte) ([]byte, error) {
+	if tr.colMissing || tr.colQuoted {
+		return b, nil
+	}
+	if tr.unescaper != nil {
+		// Append to the columns unescaped on the current row, so they remain
+		// valid for callers collecting multiple columns, e.g. RemainingBytes.
+		start := len(tr.unescapeBuf)
+		tr.unescapeBuf = tr.unescaper(tr.unescapeBuf, b)
+		return tr.unescapeBuf[start:len(tr.unescapeBuf):len(tr.unescapeBuf)], nil
+	}
+	if !tr.needUnescape || tr.noUnescape {
 		// Fast path - nothing to unescape.
-		return b
+		return b, nil
 	}
 
 	// Unescape b
 	n := bytes.IndexByte(b, '\\')
 	if n < 0 {
 		// Nothing to unescape in the current column.
-		return b
+		return b, nil
+	}
+
+	// Slow path - in-place unescaping.
+	tr.saveRow()
+	if tr.unescapeMode == UnescapeC {
+		return unescapeC(b, n)
 	}
 
-	// Slow path - in-place unescaping compatible with ClickHouse.
+	// ClickHouse-compatible unescaping.
 	n++
 	d := b[:n]
 	b = b[n:]
 	for len(b) > 0 {
-		switch b[0] {
-		case 'b':
-			d[len(d)-1] = '\b'
-		case 'f':
-			d[len(d)-1] = '\f'
-		case 'r':
-			d[len(d)-1] = '\r'
-		case 'n':
-			d[len(d)-1] = '\n'
-		case 't':
-			d[len(d)-1] = '\t'
-		case '0':
-			d[len(d)-1] = 0
-		case '\'':
-			d[len(d)-1] = '\''
-		case '\\':
-			d[len(d)-1] = '\\'
-		default:
-			d[len(d)-1] = b[0]
-		}
+		d[len(d)-1] = unescapeByte(b[0])
 
 		b = b[1:]
 		n = bytes.IndexByte(b, '\\')
@@ -238,7 +1124,147 @@ func (tr *Reader) Bytes() []byte {
 		d = append(d, b[:n]...)
 		b = b[n:]
 	}
-	return d
+	return d, nil
+}
+
+// unescapeByte returns the byte for the escape sequence `\c`.
+func unescapeByte(c byte) byte {
+	switch c {
+	case 'b':
+		return '\b'
+	case 'f':
+		return '\f'
+	case 'r':
+		return '\r'
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case '0':
+		return 0
+	default:
+		return c
+	}
+}
+
+// unescapeC unescapes b in place, additionally decoding `\xNN`
+// and `\uNNNN` escape sequences.
+//
+// n is the position of the first backslash in b.
+func unescapeC(b []byte, n int) ([]byte, error) {
+	d := b[:n]
+	for i := n; i < len(b); i++ {
+		c := b[i]
+		if c != '\\' || i+1 == len(b) {
+			d = append(d, c)
+			continue
+		}
+		i++
+		switch b[i] {
+		case 'x':
+			if i+2 >= len(b) {
+				return nil, fmt.Errorf("truncated escape sequence %q", b[i-1:])
+			}
+			x, ok := parseHex(b[i+1 : i+3])
+			if !ok {
+				return nil, fmt.Errorf("invalid escape sequence %q", b[i-1:i+3])
+			}
+			d = append(d, byte(x))
+			i += 2
+		case 'u':
+			if i+4 >= len(b) {
+				return nil, fmt.Errorf("truncated escape sequence %q", b[i-1:])
+			}
+			x, ok := parseHex(b[i+1 : i+5])
+			if !ok || utf16.IsSurrogate(rune(x)) {
+				return nil, fmt.Errorf("invalid escape sequence %q", b[i-1:i+5])
+			}
+			var buf [utf8.UTFMax]byte
+			m := utf8.EncodeRune(buf[:], rune(x))
+			d = append(d, buf[:m]...)
+			i += 4
+		default:
+			d = append(d, unescapeByte(b[i]))
+		}
+	}
+	return d, nil
+}
+
+func parseHex(b []byte) (int, bool) {
+	x := 0
+	for _, c := range b {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c -= 'a' - 10
+		case c >= 'A' && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
+		}
+		x = x<<4 | int(c)
+	}
+	return x, true
+}
+
+// UnescapeMode defines how escape sequences in columns are decoded
+// by Bytes and String.
+type UnescapeMode int
+
+const (
+	// UnescapeClickHouse decodes escape sequences produced by ClickHouse,
+	// such as `\t`, `\n` and `\\`. This is the default.
+	UnescapeClickHouse UnescapeMode = iota
+
+	// UnescapeC additionally decodes C-style `\xNN` hex escapes into bytes
+	// and `\uNNNN` escapes into UTF-8 encoded runes.
+	// Malformed escape sequences result in an error.
+	UnescapeC
+)
+
+// SetUnescapeMode sets the way escape sequences are decoded.
+func (tr *Reader) SetUnescapeMode(mode UnescapeMode) {
+	tr.unescapeMode = mode
+}
+
+// SetUnescape controls whether Bytes and String decode escape sequences.
+//
+// Disable unescaping for data using backslashes literally,
+// e.g. for Windows paths. Unescaping is enabled by default.
+func (tr *Reader) SetUnescape(unescape bool) {
+	tr.noUnescape = !unescape
+}
+
+// SetUnescaper sets the function for decoding escape sequences
+// in columns read by Bytes and String instead of the built-in unescaping.
+//
+// f must append the decoded src to dst and return the result.
+// It is called for every unquoted column, since custom escape sequences
+// may not start with a backslash. src must not be retained by f.
+// Pass nil for restoring the built-in unescaping.
+func (tr *Reader) SetUnescaper(f func(dst, src []byte) []byte) {
+	tr.unescaper = f
+}
+
+// CharsetDecoder converts text from some charset to UTF-8.
+//
+// *encoding.Decoder from golang.org/x/text/encoding satisfies this interface.
+type CharsetDecoder interface {
+	Bytes(b []byte) ([]byte, error)
+}
+
+// SetInputCharset sets the decoder for converting text columns to UTF-8.
+//
+// Only Bytes and String (and readers built on top of them) are affected,
+// so numeric and date columns are parsed without transcoding.
+// Pass nil for reading UTF-8 data as is. This is the default.
+//
+// Example:
+//
+//	tr.SetInputCharset(charmap.Windows1252.NewDecoder())
+func (tr *Reader) SetInputCharset(dec CharsetDecoder) {
+	tr.charset = dec
 }
 
 // String returns the next string column value from the current row.
@@ -250,12 +1276,15 @@ func (tr *Reader) String() string {
 
 func (tr *Reader) nextCol() ([]byte, error) {
 	if tr.row == 0 {
-		return nil, fmt.Errorf("missing Next call")
+		return nil, ErrMissingNext
 	}
 
 	tr.col++
+	if tr.slowCols {
+		return tr.nextColSlow()
+	}
 	if tr.b == nil {
-		return nil, fmt.Errorf("no more columns")
+		return nil, ErrNoMoreColumns
 	}
 
 	n := bytes.IndexByte(tr.b, tr.sep)
@@ -271,8 +1300,242 @@ func (tr *Reader) nextCol() ([]byte, error) {
 	return b, nil
 }
 
+// updateSlowCols must be called after changing settings used by nextColSlow.
+func (tr *Reader) updateSlowCols() {
+	tr.slowCols = tr.sepStr != nil || tr.quote != 0 || tr.doubledEscape ||
+		tr.trimSpace || tr.trimChars != "" || tr.maxFieldSize > 0 || tr.missingValue != nil
+
+	// nextCol doesn't reset these flags.
+	tr.colMissing = false
+	tr.colQuoted = false
+}
+
+// nextColSlow is nextCol for readers with optional column settings,
+// such as quoting, trimming or multi-byte separators.
+func (tr *Reader) nextColSlow() ([]byte, error) {
+	tr.colMissing = false
+	tr.colQuoted = false
+	if tr.b == nil {
+		if tr.missingValue != nil {
+			tr.colMissing = true
+			return tr.missingValue, nil
+		}
+		return nil, ErrNoMoreColumns
+	}
+
+	var b []byte
+	if tr.sepStr != nil {
+		if err := tr.checkSepStr(); err != nil {
+			return nil, err
+		}
+		b = tr.nextSepStrCol()
+	} else if tr.quote != 0 && len(tr.b) > 0 && tr.b[0] == tr.quote {
+		var err error
+		b, err = tr.nextQuotedCol()
+		if err != nil {
+			return nil, err
+		}
+	} else if tr.doubledEscape {
+		b = tr.nextDoubledCol()
+	} else if n := bytes.IndexByte(tr.b, tr.sep); n < 0 {
+		// last column
+		b = tr.b
+		tr.b = nil
+	} else {
+		b = tr.b[:n]
+		tr.b = tr.b[n+1:]
+	}
+	if !tr.colQuoted {
+		if tr.trimChars != "" {
+			b = bytes.Trim(b, tr.trimChars)
+		} else if tr.trimSpace {
+			b = trimSpace(b)
+		}
+	}
+
+	if tr.maxFieldSize > 0 && len(b) > tr.maxFieldSize {
+		return nil, fmt.Errorf("column size %d exceeds max field size %d", len(b), tr.maxFieldSize)
+	}
+	return b, nil
+}
+
+// checkSepStr returns an error if the multi-byte separator is combined
+// with quoting or doubled separators, which support only single-byte ones.
+func (tr *Reader) checkSepStr() error {
+	if tr.sepStr != nil && (tr.quote != 0 || tr.doubledEscape) {
+		return fmt.Errorf("quoting and doubled separators aren't supported for multi-byte separator %q", tr.sepStr)
+	}
+	return nil
+}
+
+// nextSepStrCol returns the next column from tr.b delimited
+// by the multi-byte separator.
+func (tr *Reader) nextSepStrCol() []byte {
+	b := tr.b
+	n := bytes.Index(b, tr.sepStr)
+	if n < 0 {
+		// last column
+		tr.b = nil
+		return b
+	}
+	tr.b = b[n+len(tr.sepStr):]
+	return b[:n]
+}
+
+// SetDoubledEscape controls whether a doubled separator is read
+// as a literal separator inside a column, e.g. `a||b` is read as `a|b`
+// for PSV data.
+//
+// Note that empty columns cannot be represented in the middle of rows
+// in this mode, since two adjacent separators are always read as a literal.
+// Disabled by default.
+func (tr *Reader) SetDoubledEscape(doubled bool) {
+	tr.doubledEscape = doubled
+	tr.updateSlowCols()
+}
+
+// nextDoubledCol returns the next column from tr.b, where doubled
+// separators stand for literal separators.
+func (tr *Reader) nextDoubledCol() []byte {
+	b := tr.b
+	n := bytes.IndexByte(b, tr.sep)
+	if n < 0 {
+		tr.b = nil
+		return b
+	}
+	if n+1 >= len(b) || b[n+1] != tr.sep {
+		tr.b = b[n+1:]
+		return b[:n]
+	}
+
+	// Slow path - collapse doubled separators in place.
+	tr.saveRow()
+	d := b[:n+1]
+	i := n + 2
+	for {
+		n = bytes.IndexByte(b[i:], tr.sep)
+		if n < 0 {
+			d = append(d, b[i:]...)
+			tr.b = nil
+			return d
+		}
+		d = append(d, b[i:i+n]...)
+		i += n
+		if i+1 < len(b) && b[i+1] == tr.sep {
+			d = append(d, tr.sep)
+			i += 2
+			continue
+		}
+		tr.b = b[i+1:]
+		return d
+	}
+}
+
+// At returns the current position in the form used by error messages,
+// e.g. `row #42, col #3`.
+func (tr *Reader) At() string {
+	return fmt.Sprintf("row #%d, col #%d", tr.row, tr.col)
+}
+
+// Row returns the 1-based number of the current row.
+//
+// Rows are counted the same way as in error messages, i.e. the header
+// and the rows skipped before it are counted, while rows skipped
+// by SetComment, SetRowFilter and SetSkipEmptyLines aren't counted.
+// 0 is returned before the first Next call. The last row is returned
+// after Next reaches the end of data.
+func (tr *Reader) Row() int {
+	return tr.row
+}
+
+// Col returns the number of columns consumed in the current row,
+// which is the 1-based number of the last read column.
+//
+// Col is reset to 0 by Next. SkipCol consumes a column,
+// while PeekCol and ReadAt don't.
+func (tr *Reader) Col() int {
+	return tr.col
+}
+
+// LastBadRow returns the row on which the last column error occurred.
+//
+// The returned row contains the original bytes, even if some of its columns
+// were already unescaped in place by Bytes or String.
+// This allows routing rejected rows to a dead-letter queue:
+//
+//	if err := tr.Error(); err != nil {
+//		deadLetter(tr.LastBadRow(), err)
+//		tr.ResetError()
+//		for tr.HasCols() {
+//			tr.SkipCol()
+//		}
+//	}
+//
+// The returned value is valid until the next column error or Reset call.
+func (tr *Reader) LastBadRow() []byte {
+	return tr.badRow
+}
+
+// saveRow saves the current row before it is modified in place,
+// so the original bytes remain available for diagnostics.
+func (tr *Reader) saveRow() {
+	if tr.rowMutated {
+		return
+	}
+	tr.origRow = append(tr.origRow[:0], tr.rowBuf...)
+	tr.rowMutated = true
+}
+
+// unmutatedRow returns the current row as it was before any in-place changes.
+func (tr *Reader) unmutatedRow() []byte {
+	if tr.rowMutated {
+		return tr.origRow
+	}
+	return tr.rowBuf
+}
+
+// defaultMaxErrorContext is the default limit for row bytes in error messages.
+const defaultMaxErrorContext = 256
+
+// SetMaxErrorContext limits the number of row bytes included
+// in error messages to n. Longer rows are truncated with ellipsis.
+//
+// By default rows are truncated to 256 bytes.
+// Pass n <= 0 for including the whole rows.
+func (tr *Reader) SetMaxErrorContext(n int) {
+	if n == 0 {
+		n = -1
+	}
+	tr.maxErrorContext = n
+}
+
+// quoteRow returns quoted b for error messages.
+func (tr *Reader) quoteRow(b []byte) string {
+	n := tr.maxErrorContext
+	if n == 0 {
+		n = defaultMaxErrorContext
+	}
+	if n > 0 && len(b) > n {
+		return strconv.Quote(b2s(b[:n])) + "..."
+	}
+	return strconv.Quote(b2s(b))
+}
+
 func (tr *Reader) setColError(msg string, err error) {
-	tr.err = fmt.Errorf("%s at row #%d, col #%d %q: %s", msg, tr.row, tr.col, tr.rowBuf, err)
+	row := tr.unmutatedRow()
+	tr.badRow = append(tr.badRow[:0], row...)
+	column := ""
+	if tr.col > 0 && tr.col <= len(tr.header) {
+		column = tr.header[tr.col-1]
+	}
+	tr.err = &ParseError{
+		Row:        tr.row,
+		Col:        tr.col,
+		Column:     column,
+		Err:        err,
+		msg:        msg,
+		rowContext: tr.quoteRow(row),
+	}
 }
 
 func b2s(b []byte) string {
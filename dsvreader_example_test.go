@@ -49,6 +49,24 @@ bar,123
 	// col1=bar, col2=123
 }
 
+func ExampleNewBytes() {
+	data := []byte("foo|42\nbar|123\n")
+
+	r := dsvreader.NewBytes('|', data)
+	for r.Next() {
+		col1 := r.String()
+		col2 := r.Int()
+		fmt.Printf("col1=%s, col2=%d\n", col1, col2)
+	}
+	if err := r.Error(); err != nil {
+		fmt.Printf("unexpected error: %s", err)
+	}
+
+	// Output:
+	// col1=foo, col2=42
+	// col1=bar, col2=123
+}
+
 func ExampleReader_HasCols() {
 	bs := bytes.NewBufferString(
 		"foo\n" +
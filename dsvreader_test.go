@@ -2,6 +2,10 @@ package dsvreader
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -9,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestReaderSkipCol(t *testing.T) {
@@ -1409,6 +1414,10 @@ func TestReaderDateTimeSuccess(t *testing.T) {
 	testReaderDateTimeSuccess(t, "0000-00-00 00:00:00")
 	testReaderDateTimeSuccess(t, "1970-01-01 12:34:56")
 	testReaderDateTimeSuccess(t, "2017-10-13 23:59:59")
+	testReaderDateTimeSuccess(t, "2023-01-02 03:04:05.678")
+	testReaderDateTimeSuccess(t, "2023-01-02 03:04:05.678123")
+	testReaderDateTimeSuccess(t, "2023-01-02 03:04:05.678123456")
+	testReaderDateTimeSuccess(t, "2023-01-02 03:04:05.000000001")
 }
 
 func testReaderDateTimeSuccess(t *testing.T, datetime string) {
@@ -1421,7 +1430,7 @@ func testReaderDateTimeSuccess(t *testing.T, datetime string) {
 	if r.Error() != nil {
 		t.Fatalf("unexpected error on datetime %q: %s", datetime, r.Error())
 	}
-	s := dt.Format("2006-01-02 15:04:05")
+	s := dt.Format("2006-01-02 15:04:05.999999999")
 	if s != datetime && !(datetime == "0000-00-00 00:00:00" && dt.IsZero()) {
 		t.Fatalf("unexpected datetime: %q. Expecting %q", s, datetime)
 	}
@@ -1448,6 +1457,16 @@ func TestReaderDateTimeFailure(t *testing.T) {
 	testReaderDateTimeFailure(t, "2017-01-10 10:2s:3c")
 	testReaderDateTimeFailure(t, "2017-01-10 1w:2s:3c")
 	testReaderDateTimeFailure(t, "2017-01-10 1w:2s:3c s")
+	testReaderDateTimeFailure(t, "2017-01-10 10:20:30.")
+	testReaderDateTimeFailure(t, "2017-01-10 10:20:30.1234567890")
+	testReaderDateTimeFailure(t, "2017-01-10 10:20:30.12a")
+	testReaderDateTimeFailure(t, "2017-01-10 10:20:30,123")
+	testReaderDateTimeFailure(t, "2017-01-10 10:20:30z")
+	testReaderDateTimeFailure(t, "2017-01-10 10:20:30+2")
+	testReaderDateTimeFailure(t, "2017-01-10 10:20:30+02:0")
+	testReaderDateTimeFailure(t, "2017-01-10 10:20:30+02-00")
+	testReaderDateTimeFailure(t, "2017-01-10 10:20:30+24:00")
+	testReaderDateTimeFailure(t, "2017-01-10 10:20:30.+02:00")
 }
 
 func testReaderDateTimeFailure(t *testing.T, datetime string) {
@@ -1511,3 +1530,5224 @@ func testReaderString(t *testing.T, before, after string) {
 		t.Fatalf("unexpected unescaped result: %q. Expecting %q", s, after)
 	}
 }
+
+func TestReaderBoolIntSuccess(t *testing.T) {
+	testReaderBoolIntSuccess(t, "0", false)
+	testReaderBoolIntSuccess(t, "1", true)
+}
+
+func testReaderBoolIntSuccess(t *testing.T, s string, expected bool) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.Next()
+	v := r.BoolInt()
+	if v != expected {
+		t.Fatalf("unexpected boolint for %q: %v. Expecting %v", s, v, expected)
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+}
+
+func TestReaderBoolIntFailure(t *testing.T) {
+	testReaderBoolIntFailure(t, "")
+	testReaderBoolIntFailure(t, "2")
+	testReaderBoolIntFailure(t, "17")
+	testReaderBoolIntFailure(t, "-1")
+	testReaderBoolIntFailure(t, "true")
+}
+
+func testReaderBoolIntFailure(t *testing.T, s string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.Next()
+	v := r.BoolInt()
+	if v {
+		t.Fatalf("unexpected true boolint for %q", s)
+	}
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error for %q", s)
+	}
+	errS := r.Error().Error()
+	if !strings.Contains(errS, "cannot parse `boolint`") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "cannot parse `boolint`")
+	}
+}
+
+func TestReaderLines(t *testing.T) {
+	testReaderLines(t, "", false, nil)
+	testReaderLines(t, "", true, []string{""})
+	testReaderLines(t, "foo", false, []string{"foo"})
+	testReaderLines(t, `foo\nbar`, false, []string{"foo", "bar"})
+	testReaderLines(t, `foo\nbar\n`, false, []string{"foo", "bar"})
+	testReaderLines(t, `foo\nbar\n`, true, []string{"foo", "bar", ""})
+	testReaderLines(t, `foo\n\nbar`, false, []string{"foo", "", "bar"})
+	testReaderLines(t, `a\tb\nc`, false, []string{"a\tb", "c"})
+}
+
+func testReaderLines(t *testing.T, s string, keepTrailingEmpty bool, expected []string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\tx\n")
+	r := NewTSV(b)
+	r.SetKeepTrailingEmptyLine(keepTrailingEmpty)
+	r.Next()
+	lines := r.Lines()
+	if r.Error() != nil {
+		t.Fatalf("unexpected error when parsing %q: %s", s, r.Error())
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("unexpected number of lines for %q: %d. Expecting %d", s, len(lines), len(expected))
+	}
+	for i, line := range lines {
+		if string(line) != expected[i] {
+			t.Fatalf("unexpected line #%d for %q: %q. Expecting %q", i+1, s, line, expected[i])
+		}
+	}
+	if x := r.String(); x != "x" {
+		t.Fatalf("unexpected next column: %q. Expecting %q", x, "x")
+	}
+}
+
+func TestReaderAt(t *testing.T) {
+	b := bytes.NewBufferString("foo\tbar\n1\tx\n")
+	r := NewTSV(b)
+	if s := r.At(); s != "row #0, col #0" {
+		t.Fatalf("unexpected position: %q. Expecting %q", s, "row #0, col #0")
+	}
+	r.Next()
+	r.SkipCol()
+	if s := r.At(); s != "row #1, col #1" {
+		t.Fatalf("unexpected position: %q. Expecting %q", s, "row #1, col #1")
+	}
+	r.SkipCol()
+	r.Next()
+	r.Int()
+	r.Int()
+	if s := r.At(); s != "row #2, col #2" {
+		t.Fatalf("unexpected position: %q. Expecting %q", s, "row #2, col #2")
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := err.Error(); !strings.Contains(errS, r.At()) {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, r.At())
+	}
+}
+
+func TestReaderMoneySuccess(t *testing.T) {
+	testReaderMoneySuccess(t, "USD:1234.56", "USD", 1234, 560000000)
+	testReaderMoneySuccess(t, "EUR:0", "EUR", 0, 0)
+	testReaderMoneySuccess(t, "EUR:+7.000000001", "EUR", 7, 1)
+	testReaderMoneySuccess(t, "JPY:-15.5", "JPY", -15, -500000000)
+	testReaderMoneySuccess(t, "GBP:-0.25", "GBP", 0, -250000000)
+}
+
+func testReaderMoneySuccess(t *testing.T, s, expectedCurrency string, expectedUnits int64, expectedNanos int32) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.Next()
+	currency, units, nanos, err := r.Money(':')
+	if err != nil {
+		t.Fatalf("unexpected error when parsing %q: %s", s, err)
+	}
+	if currency != expectedCurrency {
+		t.Fatalf("unexpected currency for %q: %q. Expecting %q", s, currency, expectedCurrency)
+	}
+	if units != expectedUnits {
+		t.Fatalf("unexpected units for %q: %d. Expecting %d", s, units, expectedUnits)
+	}
+	if nanos != expectedNanos {
+		t.Fatalf("unexpected nanos for %q: %d. Expecting %d", s, nanos, expectedNanos)
+	}
+}
+
+func TestReaderMoneyFailure(t *testing.T) {
+	testReaderMoneyFailure(t, "")
+	testReaderMoneyFailure(t, "USD")
+	testReaderMoneyFailure(t, "1234.56")
+	testReaderMoneyFailure(t, "usd:1")
+	testReaderMoneyFailure(t, "US:1")
+	testReaderMoneyFailure(t, "USD:")
+	testReaderMoneyFailure(t, "USD:-")
+	testReaderMoneyFailure(t, "USD:1.")
+	testReaderMoneyFailure(t, "USD:.5")
+	testReaderMoneyFailure(t, "USD:1.2.3")
+	testReaderMoneyFailure(t, "USD:1.0000000001")
+	testReaderMoneyFailure(t, "USD:1e3")
+	testReaderMoneyFailure(t, "USD:99999999999999999999")
+}
+
+func testReaderMoneyFailure(t *testing.T, s string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.Next()
+	_, _, _, err := r.Money(':')
+	if err == nil {
+		t.Fatalf("expecting non-nil error for %q", s)
+	}
+	if err != r.Error() {
+		t.Fatalf("unexpected error: %v. Expecting %v", err, r.Error())
+	}
+	errS := err.Error()
+	if !strings.Contains(errS, "cannot parse `money`") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "cannot parse `money`")
+	}
+}
+
+// latin1Decoder converts ISO 8859-1 text to UTF-8.
+type latin1Decoder struct{}
+
+func (latin1Decoder) Bytes(b []byte) ([]byte, error) {
+	d := make([]byte, 0, 2*len(b))
+	for _, c := range b {
+		d = append(d, string(rune(c))...)
+	}
+	return d, nil
+}
+
+type failingDecoder struct{}
+
+func (failingDecoder) Bytes(b []byte) ([]byte, error) {
+	return nil, fmt.Errorf("unsupported byte %q", b[0])
+}
+
+func TestReaderInputCharset(t *testing.T) {
+	b := bytes.NewBufferString("caf\xe9\t42\tna\xefve\\n\n")
+	r := NewTSV(b)
+	r.SetInputCharset(latin1Decoder{})
+	r.Next()
+	if s := r.String(); s != "café" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "café")
+	}
+	if n := r.Int(); n != 42 {
+		t.Fatalf("unexpected int: %d. Expecting %d", n, 42)
+	}
+	if bb := r.Bytes(); string(bb) != "naïve\n" {
+		t.Fatalf("unexpected bytes: %q. Expecting %q", bb, "naïve\n")
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+}
+
+func TestReaderInputCharsetError(t *testing.T) {
+	b := bytes.NewBufferString("\xff\n")
+	r := NewTSV(b)
+	r.SetInputCharset(failingDecoder{})
+	r.Next()
+	if bb := r.Bytes(); bb != nil {
+		t.Fatalf("unexpected non-nil bytes: %q", bb)
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := err.Error(); !strings.Contains(errS, "cannot decode `bytes`") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "cannot decode `bytes`")
+	}
+}
+
+func TestReaderIntN(t *testing.T) {
+	b := bytes.NewBufferString("foo\t1\t2\t3\tbar\n1\t2\n")
+	r := NewTSV(b)
+	r.Next()
+	r.SkipCol()
+	a := r.IntN(3)
+	if fmt.Sprint(a) != "[1 2 3]" {
+		t.Fatalf("unexpected ints: %v. Expecting %v", a, "[1 2 3]")
+	}
+	if s := r.String(); s != "bar" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "bar")
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+
+	// The second row is too short.
+	r.Next()
+	a = r.IntN(3)
+	if a != nil {
+		t.Fatalf("unexpected non-nil ints: %v", a)
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := err.Error(); !strings.Contains(errS, "no more columns") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "no more columns")
+	}
+
+	// Subsequent calls must return nil.
+	if a := r.IntN(0); a != nil {
+		t.Fatalf("unexpected non-nil ints after error: %v", a)
+	}
+}
+
+func TestReaderNegativeN(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("1\t2\n"))
+	r.Next()
+	if a := r.IntN(-1); a != nil {
+		t.Fatalf("unexpected non-nil ints: %v", a)
+	}
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := r.Error().Error(); !strings.Contains(errS, "negative number of columns") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "negative number of columns")
+	}
+
+	r.ResetError()
+	if a := r.Float64N(-2); a != nil {
+		t.Fatalf("unexpected non-nil floats: %v", a)
+	}
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	r.ResetError()
+	if a := r.StringN(-3); a != nil {
+		t.Fatalf("unexpected non-nil strings: %q", a)
+	}
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	// The columns are left unread.
+	r.ResetError()
+	if a := r.IntN(2); fmt.Sprint(a) != "[1 2]" {
+		t.Fatalf("unexpected ints: %v. Expecting %v", a, "[1 2]")
+	}
+}
+
+func TestReaderFloat64N(t *testing.T) {
+	b := bytes.NewBufferString("1.5\t-2\t3e2\n1\tfoo\n")
+	r := NewTSV(b)
+	r.Next()
+	a := r.Float64N(3)
+	if fmt.Sprint(a) != "[1.5 -2 300]" {
+		t.Fatalf("unexpected floats: %v. Expecting %v", a, "[1.5 -2 300]")
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+
+	r.Next()
+	a = r.Float64N(2)
+	if a != nil {
+		t.Fatalf("unexpected non-nil floats: %v", a)
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := err.Error(); !strings.Contains(errS, "cannot parse `float64`") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "cannot parse `float64`")
+	}
+}
+
+func TestReaderStringN(t *testing.T) {
+	b := bytes.NewBufferString("foo\tb\\tar\t\n")
+	r := NewTSV(b)
+	r.Next()
+	a := r.StringN(3)
+	if fmt.Sprintf("%q", a) != `["foo" "b\tar" ""]` {
+		t.Fatalf("unexpected strings: %q", a)
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+	if a := r.StringN(1); a != nil {
+		t.Fatalf("unexpected non-nil strings: %q", a)
+	}
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderLastBadRow(t *testing.T) {
+	b := bytes.NewBufferString("1\ta\\tb\t2\n2\tc\\\\d\tfoo\n3\te\t4\n")
+	r := NewTSV(b)
+	if bb := r.LastBadRow(); len(bb) > 0 {
+		t.Fatalf("unexpected non-empty bad row: %q", bb)
+	}
+
+	var sum int
+	var badRows []string
+	for r.Next() {
+		sum += r.Int()
+		r.Bytes()
+		sum += r.Int()
+		if err := r.Error(); err != nil {
+			badRows = append(badRows, string(r.LastBadRow()))
+			r.ResetError()
+			for r.HasCols() {
+				r.SkipCol()
+			}
+		}
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if sum != 12 {
+		t.Fatalf("unexpected sum: %d. Expecting %d", sum, 12)
+	}
+	if len(badRows) != 1 {
+		t.Fatalf("unexpected number of bad rows: %d. Expecting 1", len(badRows))
+	}
+	// The bad row must contain the original bytes despite in-place unescaping.
+	if badRows[0] != "2\tc\\\\d\tfoo" {
+		t.Fatalf("unexpected bad row: %q. Expecting %q", badRows[0], "2\tc\\\\d\tfoo")
+	}
+}
+
+func TestCountRows(t *testing.T) {
+	testCountRows(t, "", 0)
+	testCountRows(t, "\n", 1)
+	testCountRows(t, "foo\tbar\n", 1)
+	testCountRows(t, "foo\tbar\n\n1\t2\n", 3)
+	testCountRows(t, strings.Repeat("a,b,c\n", 100000), 100000)
+}
+
+func testCountRows(t *testing.T, s string, expectedRows int) {
+	t.Helper()
+
+	rows, err := CountRows(bytes.NewBufferString(s), '\t')
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rows != expectedRows {
+		t.Fatalf("unexpected number of rows: %d. Expecting %d", rows, expectedRows)
+	}
+
+	// The result must be independent of the size of chunks returned by reader.
+	rows, err = CountRows(&slowSource{s: []byte(s)}, '\t')
+	if err != nil {
+		t.Fatalf("unexpected error for slow source: %s", err)
+	}
+	if rows != expectedRows {
+		t.Fatalf("unexpected number of rows for slow source: %d. Expecting %d", rows, expectedRows)
+	}
+}
+
+func TestCountRowsNoNewline(t *testing.T) {
+	rows, err := CountRows(bytes.NewBufferString("foo\nbar"), '\t')
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if rows != 1 {
+		t.Fatalf("unexpected number of rows: %d. Expecting 1", rows)
+	}
+	if errS := err.Error(); !strings.Contains(errS, "cannot find newline at the end of row #2") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "cannot find newline at the end of row #2")
+	}
+}
+
+func TestReaderPointSuccess(t *testing.T) {
+	testReaderPointSuccess(t, "53.9,27.56", 53.9, 27.56)
+	testReaderPointSuccess(t, "-33.8688,151.2093", -33.8688, 151.2093)
+	testReaderPointSuccess(t, "0,0", 0, 0)
+}
+
+func testReaderPointSuccess(t *testing.T, s string, expectedA, expectedB float64) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.Next()
+	a, bb, err := r.Point(',')
+	if err != nil {
+		t.Fatalf("unexpected error when parsing %q: %s", s, err)
+	}
+	if a != expectedA || bb != expectedB {
+		t.Fatalf("unexpected point for %q: (%v, %v). Expecting (%v, %v)", s, a, bb, expectedA, expectedB)
+	}
+}
+
+func TestReaderPointFailure(t *testing.T) {
+	testReaderPointFailure(t, "")
+	testReaderPointFailure(t, "53.9")
+	testReaderPointFailure(t, "1,2,3")
+	testReaderPointFailure(t, "foo,1")
+	testReaderPointFailure(t, "1,bar")
+	testReaderPointFailure(t, ",")
+}
+
+func testReaderPointFailure(t *testing.T, s string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.Next()
+	_, _, err := r.Point(',')
+	if err == nil {
+		t.Fatalf("expecting non-nil error for %q", s)
+	}
+	if errS := err.Error(); !strings.Contains(errS, "cannot parse `point`") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "cannot parse `point`")
+	}
+}
+
+func TestReaderTabExpand(t *testing.T) {
+	testReaderTabExpand(t, 4, "", "")
+	testReaderTabExpand(t, 4, "a", "a")
+	testReaderTabExpand(t, 4, "\ta", "    a")
+	testReaderTabExpand(t, 4, "ab\tc", "ab  c")
+	testReaderTabExpand(t, 4, "abcd\te", "abcd    e")
+	testReaderTabExpand(t, 4, "a\t\tb", "a       b")
+	testReaderTabExpand(t, 8, "a;b\t;c", "a;b     ;c")
+	testReaderTabExpand(t, 0, "a\tb", "a\tb")
+}
+
+func testReaderTabExpand(t *testing.T, width int, s, expected string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n" + s + "\n")
+	r := NewCustom(';', b)
+	r.SetTabExpand(width)
+	for i := 0; i < 2; i++ {
+		if !r.Next() {
+			t.Fatalf("Next must return true")
+		}
+		var cols []string
+		for r.HasCols() {
+			cols = append(cols, r.String())
+		}
+		if got := strings.Join(cols, ";"); got != expected {
+			t.Fatalf("unexpected expanded row for %q: %q. Expecting %q", s, got, expected)
+		}
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+}
+
+func TestReaderExpectHeaderSuccess(t *testing.T) {
+	testReaderExpectHeaderSuccess(t, false, "id\tname\tage", "id", "name", "age")
+	testReaderExpectHeaderSuccess(t, true, "id\tname\tage", "id", "name", "age")
+	testReaderExpectHeaderSuccess(t, true, "age\tid\tname", "id", "name", "age")
+	testReaderExpectHeaderSuccess(t, true, "a\tb\ta", "a", "a", "b")
+}
+
+func testReaderExpectHeaderSuccess(t *testing.T, anyOrder bool, header string, names ...string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(header + "\n1\t2\t3\n")
+	r := NewTSV(b)
+	r.SetHeaderAnyOrder(anyOrder)
+	if err := r.ExpectHeader(names...); err != nil {
+		t.Fatalf("unexpected error for header %q: %s", header, err)
+	}
+	if !r.Next() {
+		t.Fatalf("Next must return true after the header")
+	}
+	if a := r.IntN(3); fmt.Sprint(a) != "[1 2 3]" {
+		t.Fatalf("unexpected data row: %v", a)
+	}
+}
+
+func TestReaderExpectHeaderFailure(t *testing.T) {
+	testReaderExpectHeaderFailure(t, false, "", "cannot find header row", "id")
+	testReaderExpectHeaderFailure(t, false, "id\tname\n", `col #2 is "name" instead of "age"`, "id", "age")
+	testReaderExpectHeaderFailure(t, false, "name\tid\n", `col #1 is "name" instead of "id"`, "id", "name")
+	testReaderExpectHeaderFailure(t, false, "id\n", `missing col #2 "name"`, "id", "name")
+	testReaderExpectHeaderFailure(t, false, "id\tname\tage\n", `unexpected col #3 "age"`, "id", "name")
+	testReaderExpectHeaderFailure(t, true, "name\tage\n", `missing col "id"; unexpected col "age"`, "id", "name")
+	testReaderExpectHeaderFailure(t, true, "id\tid\n", `missing col "name"; unexpected col "id"`, "id", "name")
+}
+
+func testReaderExpectHeaderFailure(t *testing.T, anyOrder bool, header, expectedErr string, names ...string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(header)
+	r := NewTSV(b)
+	r.SetHeaderAnyOrder(anyOrder)
+	err := r.ExpectHeader(names...)
+	if err == nil {
+		t.Fatalf("expecting non-nil error for header %q", header)
+	}
+	if err != r.Error() {
+		t.Fatalf("unexpected error: %v. Expecting %v", err, r.Error())
+	}
+	if errS := err.Error(); !strings.Contains(errS, expectedErr) {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, expectedErr)
+	}
+}
+
+func TestReaderHeaderColumnError(t *testing.T) {
+	// The header must stop at the first column error instead of looping forever.
+	for _, tc := range []struct {
+		data    string
+		setup   func(r *Reader)
+		wantErr string
+	}{
+		{"aaaaaaaaaa,b\n1,2\n", func(r *Reader) { r.SetMaxFieldSize(3) }, "exceeds max field size"},
+		{"a\\xZZ,b\n1,2\n", func(r *Reader) { r.SetUnescapeMode(UnescapeC) }, "cannot unescape"},
+	} {
+		r := NewCSV(bytes.NewBufferString(tc.data))
+		tc.setup(r)
+		if header := r.Header(); header != nil {
+			t.Fatalf("unexpected non-nil header for %q: %q", tc.data, header)
+		}
+		err := r.Error()
+		if err == nil {
+			t.Fatalf("expecting non-nil error for %q", tc.data)
+		}
+		if errS := err.Error(); !strings.Contains(errS, tc.wantErr) {
+			t.Fatalf("unexpected error: %s. Must contain %q", errS, tc.wantErr)
+		}
+
+		r = NewCSV(bytes.NewBufferString(tc.data))
+		tc.setup(r)
+		if err := r.ExpectHeader("a", "b"); err == nil {
+			t.Fatalf("expecting non-nil error for %q", tc.data)
+		}
+	}
+}
+
+func TestReaderPercentSuccess(t *testing.T) {
+	testReaderPercentSuccess(t, "12.5%", false, 12.5)
+	testReaderPercentSuccess(t, "+12.5%", false, 12.5)
+	testReaderPercentSuccess(t, "-3.2%", false, -3.2)
+	testReaderPercentSuccess(t, "0%", false, 0)
+	testReaderPercentSuccess(t, ".5%", false, 0.5)
+	testReaderPercentSuccess(t, "150%", false, 150)
+	testReaderPercentSuccess(t, "12.5%", true, 0.125)
+	testReaderPercentSuccess(t, "+12.5%", true, 0.125)
+	testReaderPercentSuccess(t, "-3.2%", true, -0.032)
+}
+
+func testReaderPercentSuccess(t *testing.T, s string, asFraction bool, expected float64) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.SetPercentAsFraction(asFraction)
+	r.Next()
+	f := r.Percent()
+	if r.Error() != nil {
+		t.Fatalf("unexpected error when parsing %q: %s", s, r.Error())
+	}
+	if f != expected {
+		t.Fatalf("unexpected percent for %q: %v. Expecting %v", s, f, expected)
+	}
+}
+
+func TestReaderPercentFailure(t *testing.T) {
+	testReaderPercentFailure(t, "")
+	testReaderPercentFailure(t, "%")
+	testReaderPercentFailure(t, "+%")
+	testReaderPercentFailure(t, "%12")
+	testReaderPercentFailure(t, "12")
+	testReaderPercentFailure(t, "12%%")
+	testReaderPercentFailure(t, "+-12%")
+	testReaderPercentFailure(t, "--12%")
+	testReaderPercentFailure(t, "12 %")
+	testReaderPercentFailure(t, "inf%")
+	testReaderPercentFailure(t, "foo%")
+}
+
+func testReaderPercentFailure(t *testing.T, s string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.Next()
+	f := r.Percent()
+	if f != 0 {
+		t.Fatalf("unexpected non-zero percent for %q: %v", s, f)
+	}
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error for %q", s)
+	}
+	if errS := r.Error().Error(); !strings.Contains(errS, "cannot parse `percent`") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "cannot parse `percent`")
+	}
+}
+
+func TestReaderDisableColumnSettings(t *testing.T) {
+	b := bytes.NewBufferString("\" a \"\t\t b \n")
+	r := NewTSV(b)
+	r.SetQuoting('"')
+	r.SetTrimSpace(true)
+	r.SetMaxFieldSize(3)
+	r.Next()
+	if s, quoted := r.FieldQuoted(); string(s) != " a " || !quoted {
+		t.Fatalf("unexpected field: %q, quoted=%v. Expecting %q, quoted=true", s, quoted, " a ")
+	}
+
+	// Columns must be read as is after disabling the settings.
+	r.SetQuoting(0)
+	r.SetTrimSpace(false)
+	r.SetMaxFieldSize(0)
+	if _, ok := r.NullableBytes(); ok {
+		t.Fatalf("expecting NULL for the empty column")
+	}
+	if s := r.String(); s != " b " {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, " b ")
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+}
+
+func TestReaderMaxFieldSize(t *testing.T) {
+	b := bytes.NewBufferString("foo\tbar\n1234\t\n")
+	r := NewTSV(b)
+	r.SetMaxFieldSize(3)
+	r.Next()
+	if s := r.String(); s != "foo" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "foo")
+	}
+	if s := r.String(); s != "bar" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "bar")
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+
+	r.Next()
+	if n := r.Int(); n != 0 {
+		t.Fatalf("unexpected non-zero int: %d", n)
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	expectedErr := "at row #2, col #1"
+	if errS := err.Error(); !strings.Contains(errS, expectedErr) || !strings.Contains(errS, "exceeds max field size 3") {
+		t.Fatalf("unexpected error: %s. Must contain %q and the max field size", errS, expectedErr)
+	}
+
+	// Zero means unlimited size.
+	b = bytes.NewBufferString("1234\n")
+	r = NewTSV(b)
+	r.SetMaxFieldSize(0)
+	r.Next()
+	if n := r.Int(); n != 1234 {
+		t.Fatalf("unexpected int: %d. Expecting %d", n, 1234)
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+}
+
+func TestReaderPeekRow(t *testing.T) {
+	b := bytes.NewBufferString("foo\t1\nbar\t2\nbaz\t3\n")
+	r := NewTSV(b)
+
+	// PeekRow before the first Next returns the first row.
+	row, ok := r.PeekRow()
+	if !ok || string(row) != "foo\t1" {
+		t.Fatalf("unexpected peeked row: %q, %v. Expecting %q", row, ok, "foo\t1")
+	}
+	if !r.Next() {
+		t.Fatalf("Next must return true")
+	}
+	if s := r.String(); s != "foo" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "foo")
+	}
+
+	// Peeking must leave the current row intact.
+	for i := 0; i < 3; i++ {
+		row, ok = r.PeekRow()
+		if !ok || string(row) != "bar\t2" {
+			t.Fatalf("unexpected peeked row: %q, %v. Expecting %q", row, ok, "bar\t2")
+		}
+	}
+	if n := r.Int(); n != 1 {
+		t.Fatalf("unexpected int: %d. Expecting %d", n, 1)
+	}
+	if r.At() != "row #1, col #2" {
+		t.Fatalf("unexpected position: %q", r.At())
+	}
+
+	if !r.Next() {
+		t.Fatalf("Next must return true")
+	}
+	if s, n := r.String(), r.Int(); s != "bar" || n != 2 {
+		t.Fatalf("unexpected row: %q, %d. Expecting %q, %d", s, n, "bar", 2)
+	}
+	if !r.Next() {
+		t.Fatalf("Next must return true")
+	}
+	if s, n := r.String(), r.Int(); s != "baz" || n != 3 {
+		t.Fatalf("unexpected row: %q, %d. Expecting %q, %d", s, n, "baz", 3)
+	}
+
+	// No more rows.
+	if row, ok = r.PeekRow(); ok {
+		t.Fatalf("unexpected peeked row at the end of data: %q", row)
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+	if r.Next() {
+		t.Fatalf("Next must return false")
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+}
+
+func TestReaderPeekRowNoNewline(t *testing.T) {
+	b := bytes.NewBufferString("foo\nbar")
+	r := NewTSV(b)
+	r.Next()
+	if _, ok := r.PeekRow(); ok {
+		t.Fatalf("PeekRow must return false for the row without newline")
+	}
+	if s := r.String(); s != "foo" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "foo")
+	}
+	if r.Next() {
+		t.Fatalf("Next must return false")
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := err.Error(); !strings.Contains(errS, "cannot find newline at the end of row #2") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "cannot find newline at the end of row #2")
+	}
+}
+
+func TestReaderPeekRowSlowSource(t *testing.T) {
+	for _, rows := range []int{1, 10, 100} {
+		for _, cols := range []int{1, 10, 100} {
+			testReaderPeekRowSlowSource(t, rows, cols)
+		}
+	}
+}
+
+func testReaderPeekRowSlowSource(t *testing.T, rows, cols int) {
+	t.Helper()
+
+	expected := make([][]string, rows)
+	var rowsS []string
+	for i := range expected {
+		row := make([]string, cols)
+		for j := range row {
+			row[j] = fmt.Sprintf("c%d_%d\\n", i, j)
+		}
+		expected[i] = row
+		rowsS = append(rowsS, strings.Join(row, "\t"))
+	}
+	ss := &slowSource{s: []byte(strings.Join(rowsS, "\n") + "\n")}
+	r := NewTSV(ss)
+	for i := 0; i < rows; i++ {
+		if !r.Next() {
+			t.Fatalf("Next must return true on row #%d; err: %v", i+1, r.Error())
+		}
+		for j := 0; j < cols; j++ {
+			if j == cols/2 {
+				row, ok := r.PeekRow()
+				if i+1 < rows && (!ok || string(row) != rowsS[i+1]) {
+					t.Fatalf("unexpected peeked row #%d: %q, %v. Expecting %q", i+2, row, ok, rowsS[i+1])
+				}
+			}
+			s := r.String()
+			e := strings.Replace(expected[i][j], `\n`, "\n", 1)
+			if s != e {
+				t.Fatalf("unexpected string on row #%d, col #%d: %q. Expecting %q", i+1, j+1, s, e)
+			}
+		}
+	}
+	if r.Next() {
+		t.Fatalf("Next must return false")
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+}
+
+func TestReaderUnescapeAcrossReads(t *testing.T) {
+	// The escape sequence is in the first chunk, while the rest
+	// of the row is in the second chunk without escape sequences.
+	ss := &chunkSource{chunks: []string{"a\\tb\tc", "d\n"}}
+	r := NewTSV(ss)
+	r.Next()
+	if s := r.String(); s != "a\tb" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "a\tb")
+	}
+	if s := r.String(); s != "cd" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "cd")
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+}
+
+// chunkSource returns data by the given chunks.
+type chunkSource struct {
+	chunks []string
+}
+
+func (cs *chunkSource) Read(p []byte) (int, error) {
+	if len(cs.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, cs.chunks[0])
+	cs.chunks = cs.chunks[1:]
+	return n, nil
+}
+
+func TestReaderCommentString(t *testing.T) {
+	testReaderCommentString(t, "//", "// header\nfoo\t1\n//bar\t2\n/baz\t3\n", "foo:1,/baz:3,")
+	testReaderCommentString(t, "--", "--\n-- x\nfoo\t1\nbar\t-- 2\n", "foo:1,bar:-- 2,")
+	testReaderCommentString(t, "--", "foo\t1\n  -- x\t2\n", "foo:1,  -- x:2,")
+	testReaderCommentString(t, "", "//foo\t1\n", "//foo:1,")
+	testReaderCommentString(t, "#", "#\n#\n", "")
+}
+
+func testReaderCommentString(t *testing.T, prefix, s, expected string) {
+	t.Helper()
+
+	for _, src := range []io.Reader{bytes.NewBufferString(s), &slowSource{s: []byte(s)}} {
+		r := NewTSV(src)
+		r.SetCommentString(prefix)
+		var result string
+		for r.Next() {
+			result += r.String() + ":" + r.String() + ","
+		}
+		if r.Error() != nil {
+			t.Fatalf("unexpected error: %s", r.Error())
+		}
+		if result != expected {
+			t.Fatalf("unexpected result for %q: %q. Expecting %q", s, result, expected)
+		}
+	}
+}
+
+func TestReaderCommentStringSplit(t *testing.T) {
+	// The comment prefix is split between reads.
+	ss := &chunkSource{chunks: []string{"foo\n/", "/ comment\nbar\n"}}
+	r := NewTSV(ss)
+	r.SetCommentString("//")
+	var result, positions []string
+	for r.Next() {
+		result = append(result, r.String())
+		positions = append(positions, r.At())
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+	if fmt.Sprint(result) != "[foo bar]" {
+		t.Fatalf("unexpected result: %q. Expecting %q", result, "[foo bar]")
+	}
+	if positions[1] != "row #2, col #1" {
+		t.Fatalf("unexpected position: %q. Expecting %q", positions[1], "row #2, col #1")
+	}
+}
+
+func TestReaderSetLocationName(t *testing.T) {
+	b := bytes.NewBufferString("Europe/Berlin\t2021-03-01 12:00:00\n" +
+		"America/New_York\t2021-03-01 12:00:00\n" +
+		"\t2021-03-01 12:00:00\n" +
+		"Europe/Berlin\t2021-07-01 12:00:00\n")
+	r := NewTSV(b)
+	var result []string
+	for r.Next() {
+		r.SetLocationName(r.String())
+		dt := r.DateTime()
+		result = append(result, dt.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+	expected := "[2021-03-01 11:00:00 2021-03-01 17:00:00 2021-03-01 12:00:00 2021-07-01 10:00:00]"
+	if fmt.Sprint(result) != expected {
+		t.Fatalf("unexpected result: %q. Expecting %q", result, expected)
+	}
+}
+
+func TestReaderSetLocationNameFailure(t *testing.T) {
+	b := bytes.NewBufferString("Mars/Olympus_Mons\t2021-03-01 12:00:00\n")
+	r := NewTSV(b)
+	r.Next()
+	r.SetLocationName(r.String())
+	dt := r.DateTime()
+	if !dt.IsZero() {
+		t.Fatalf("unexpected non-zero datetime: %s", dt)
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := err.Error(); !strings.Contains(errS, "cannot load location at row #1, col #1") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "cannot load location at row #1, col #1")
+	}
+}
+
+func TestReaderRawLine(t *testing.T) {
+	s := "foo\ta\\tb\t1\n\nbar\tc\\\\d\t2\n"
+	for _, src := range []io.Reader{bytes.NewBufferString(s), &slowSource{s: []byte(s)}} {
+		r := NewTSV(src)
+		if line := r.RawLine(); line != nil {
+			t.Fatalf("unexpected non-nil line before Next: %q", line)
+		}
+		var result []byte
+		for r.Next() {
+			for r.HasCols() {
+				r.Bytes()
+			}
+			result = append(result, r.RawLine()...)
+		}
+		if r.Error() != nil {
+			t.Fatalf("unexpected error: %s", r.Error())
+		}
+		if string(result) != s {
+			t.Fatalf("unexpected result: %q. Expecting %q", result, s)
+		}
+	}
+}
+
+func TestReaderRawLineTabExpand(t *testing.T) {
+	b := bytes.NewBufferString("a\tb;c\nd\t\te\n")
+	r := NewCustom(';', b)
+	r.SetTabExpand(4)
+	r.Next()
+	if s := r.String(); s != "a   b" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "a   b")
+	}
+	if line := r.RawLine(); string(line) != "a\tb;c\n" {
+		t.Fatalf("unexpected line: %q. Expecting %q", line, "a\tb;c\n")
+	}
+
+	// The raw line must survive PeekRow.
+	if row, ok := r.PeekRow(); !ok || string(row) != "d\t\te" {
+		t.Fatalf("unexpected peeked row: %q. Expecting %q", row, "d\t\te")
+	}
+	if line := r.RawLine(); string(line) != "a\tb;c\n" {
+		t.Fatalf("unexpected line after PeekRow: %q. Expecting %q", line, "a\tb;c\n")
+	}
+	if s := r.String(); s != "c" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "c")
+	}
+	r.Next()
+	if line := r.RawLine(); string(line) != "d\t\te\n" {
+		t.Fatalf("unexpected line: %q. Expecting %q", line, "d\t\te\n")
+	}
+}
+
+func TestReaderUnescapeModeC(t *testing.T) {
+	testReaderUnescapeModeC(t, `\x41\x62c`, "Abc")
+	testReaderUnescapeModeC(t, `\xff\x00`, "\xff\x00")
+	testReaderUnescapeModeC(t, `caf\u00e9`, "café")
+	testReaderUnescapeModeC(t, `\u20AC1`, "€1")
+	testReaderUnescapeModeC(t, `a\tb\\x41`, "a\tb\\x41")
+	testReaderUnescapeModeC(t, `\n\x41B\0`, "\nAB\x00")
+	testReaderUnescapeModeC(t, `\`, `\`)
+}
+
+func testReaderUnescapeModeC(t *testing.T, before, after string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(before + "\t" + before + "\n")
+	r := NewTSV(b)
+	r.SetUnescapeMode(UnescapeC)
+	r.Next()
+	for i := 0; i < 2; i++ {
+		bb := r.Bytes()
+		if r.Error() != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", before, r.Error())
+		}
+		if string(bb) != after {
+			t.Fatalf("unexpected unescaped result: %q. Expecting %q", bb, after)
+		}
+	}
+}
+
+func TestReaderUnescapeModeCFailure(t *testing.T) {
+	testReaderUnescapeModeCFailure(t, `\x`)
+	testReaderUnescapeModeCFailure(t, `\x4`)
+	testReaderUnescapeModeCFailure(t, `\x4g`)
+	testReaderUnescapeModeCFailure(t, `\u12`)
+	testReaderUnescapeModeCFailure(t, `\u12x4`)
+	testReaderUnescapeModeCFailure(t, `\ud800`)
+}
+
+func testReaderUnescapeModeCFailure(t *testing.T, s string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.SetUnescapeMode(UnescapeC)
+	r.Next()
+	if bb := r.Bytes(); bb != nil {
+		t.Fatalf("unexpected non-nil bytes for %q: %q", s, bb)
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error for %q", s)
+	}
+	if errS := err.Error(); !strings.Contains(errS, "cannot unescape `bytes`") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "cannot unescape `bytes`")
+	}
+
+	// The default mode passes such sequences through.
+	b = bytes.NewBufferString(s + "\n")
+	r = NewTSV(b)
+	r.Next()
+	r.Bytes()
+	if r.Error() != nil {
+		t.Fatalf("unexpected error for %q in the default mode: %s", s, r.Error())
+	}
+}
+
+func TestReaderHeader(t *testing.T) {
+	b := bytes.NewBufferString("id\tname\tage\n1\tfoo\t42\n")
+	r := NewTSV(b)
+	header := r.Header()
+	if fmt.Sprint(header) != "[id name age]" {
+		t.Fatalf("unexpected header: %q. Expecting %q", header, "[id name age]")
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+	if !r.Next() {
+		t.Fatalf("Next must return true after the header")
+	}
+	if n, s, age := r.Int(), r.String(), r.Int(); n != 1 || s != "foo" || age != 42 {
+		t.Fatalf("unexpected row: %d, %q, %d", n, s, age)
+	}
+
+	// Missing header.
+	r = NewTSV(bytes.NewBufferString(""))
+	if header := r.Header(); header != nil {
+		t.Fatalf("unexpected non-nil header: %q", header)
+	}
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderRequiredColumns(t *testing.T) {
+	testReaderRequiredColumns(t, "id\tname\tage", "", "id", "age")
+	testReaderRequiredColumns(t, "age\textra\tid", "", "id", "age")
+	testReaderRequiredColumns(t, "id\tname", "", "id", "id")
+	testReaderRequiredColumns(t, "id\tname", "", nil...)
+	testReaderRequiredColumns(t, "id\tname", `missing required columns ["age"]`, "id", "age")
+	testReaderRequiredColumns(t, "name", `missing required columns ["id" "age"]`, "id", "name", "age")
+}
+
+func testReaderRequiredColumns(t *testing.T, header, expectedErr string, names ...string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(header + "\n")
+	r := NewTSV(b)
+	r.SetRequiredColumns(names...)
+	h := r.Header()
+	err := r.Error()
+	if expectedErr == "" {
+		if err != nil {
+			t.Fatalf("unexpected error for header %q: %s", header, err)
+		}
+		if strings.Join(h, "\t") != header {
+			t.Fatalf("unexpected header: %q. Expecting %q", h, header)
+		}
+		return
+	}
+	if h != nil {
+		t.Fatalf("unexpected non-nil header: %q", h)
+	}
+	if err == nil {
+		t.Fatalf("expecting non-nil error for header %q", header)
+	}
+	if errS := err.Error(); !strings.Contains(errS, expectedErr) {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, expectedErr)
+	}
+}
+
+func TestReadAt(t *testing.T) {
+	b := bytes.NewBufferString("foo\t42\t1.5\t2021-03-01 12:00:00\ta\\tb\n")
+	r := NewTSV(b)
+	r.Next()
+	if n := ReadAt[int](r, 1); n != 42 {
+		t.Fatalf("unexpected int: %d. Expecting %d", n, 42)
+	}
+	if n := ReadAt[uint8](r, 1); n != 42 {
+		t.Fatalf("unexpected uint8: %d. Expecting %d", n, 42)
+	}
+	if f := ReadAt[float64](r, 2); f != 1.5 {
+		t.Fatalf("unexpected float64: %v. Expecting %v", f, 1.5)
+	}
+	if dt := ReadAt[time.Time](r, 3); dt.Format("2006-01-02 15:04:05") != "2021-03-01 12:00:00" {
+		t.Fatalf("unexpected time: %s", dt)
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+
+	// Mix ReadAt with column readers.
+	if s := r.String(); s != "foo" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "foo")
+	}
+	for i := 0; i < 2; i++ {
+		if s := ReadAt[string](r, 4); s != "a\tb" {
+			t.Fatalf("unexpected string: %q. Expecting %q", s, "a\tb")
+		}
+	}
+	if s := ReadAt[string](r, 0); s != "foo" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "foo")
+	}
+	if n := r.Int(); n != 42 {
+		t.Fatalf("unexpected int: %d. Expecting %d", n, 42)
+	}
+	r.SkipCol()
+	r.SkipCol()
+	if s := r.String(); s != "a\tb" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "a\tb")
+	}
+	if s := ReadAt[string](r, 4); s != "a\tb" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "a\tb")
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+	if r.HasCols() {
+		t.Fatalf("HasCols must return false")
+	}
+}
+
+func TestReadAtFailure(t *testing.T) {
+	testReadAtFailure(t, func(r *Reader) { ReadAt[int](r, 0) }, "cannot parse `int` at row #1, col #1")
+	testReadAtFailure(t, func(r *Reader) { ReadAt[int](r, 5) }, "no more columns")
+	testReadAtFailure(t, func(r *Reader) { ReadAt[complex64](r, 1) }, "unsupported type *complex64")
+}
+
+func testReadAtFailure(t *testing.T, f func(r *Reader), expectedErr string) {
+	t.Helper()
+
+	b := bytes.NewBufferString("foo\t42\n")
+	r := NewTSV(b)
+	r.Next()
+	f(r)
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := err.Error(); !strings.Contains(errS, expectedErr) {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, expectedErr)
+	}
+}
+
+func TestReaderNaNTokens(t *testing.T) {
+	b := bytes.NewBufferString("1.5\tNA\tN/A\t-\tnan\tna\n")
+	r := NewTSV(b)
+	r.SetNaNTokens("NA", "N/A", "-")
+	r.Next()
+	if f := r.Float64(); f != 1.5 {
+		t.Fatalf("unexpected float64: %v. Expecting %v", f, 1.5)
+	}
+	for i := 0; i < 3; i++ {
+		if f := r.Float64(); !math.IsNaN(f) {
+			t.Fatalf("unexpected float64 on col #%d: %v. Expecting NaN", i+2, f)
+		}
+	}
+	// strconv.ParseFloat accepts `nan` on its own.
+	if f := r.Float32(); !math.IsNaN(float64(f)) {
+		t.Fatalf("unexpected float32: %v. Expecting NaN", f)
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+
+	// Tokens are case-sensitive by default.
+	if f := r.Float64(); f != 0 {
+		t.Fatalf("unexpected non-zero float64: %v", f)
+	}
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderNaNTokensCaseInsensitive(t *testing.T) {
+	b := bytes.NewBufferString("na\tN/a\tn/A\n")
+	r := NewTSV(b)
+	r.SetNaNTokens("NA", "N/A")
+	r.SetNaNTokensCaseInsensitive(true)
+	r.Next()
+	if f := r.Float32(); !math.IsNaN(float64(f)) {
+		t.Fatalf("unexpected float32: %v. Expecting NaN", f)
+	}
+	for i := 0; i < 2; i++ {
+		if f := r.Float64(); !math.IsNaN(f) {
+			t.Fatalf("unexpected float64: %v. Expecting NaN", f)
+		}
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+}
+
+func TestReaderNaNTokensDisabled(t *testing.T) {
+	b := bytes.NewBufferString("NA\n")
+	r := NewTSV(b)
+	r.Next()
+	if f := r.Float64(); f != 0 {
+		t.Fatalf("unexpected non-zero float64: %v", f)
+	}
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderRejectNonFinite(t *testing.T) {
+	for _, s := range []string{"NaN", "+Inf", "-Inf"} {
+		// Non-finite values are accepted by default.
+		r := NewTSV(bytes.NewBufferString(s + "\t" + s + "\n"))
+		r.Next()
+		if f := r.Float64(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			t.Fatalf("unexpected float64 for %q: %v. Expecting non-finite value", s, f)
+		}
+		if f := r.Float32(); !math.IsNaN(float64(f)) && !math.IsInf(float64(f), 0) {
+			t.Fatalf("unexpected float32 for %q: %v. Expecting non-finite value", s, f)
+		}
+		if r.Error() != nil {
+			t.Fatalf("unexpected error for %q: %s", s, r.Error())
+		}
+
+		r = NewTSV(bytes.NewBufferString(s + "\n" + s + "\n"))
+		r.SetRejectNonFinite(true)
+		r.Next()
+		if f := r.Float64(); f != 0 {
+			t.Fatalf("unexpected non-zero float64 for %q: %v", s, f)
+		}
+		if r.Error() == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+		r.SkipToNextRow()
+		r.Next()
+		if f := r.Float32(); f != 0 {
+			t.Fatalf("unexpected non-zero float32 for %q: %v", s, f)
+		}
+		if r.Error() == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+
+	r := NewTSV(bytes.NewBufferString("1.5\t-2.25\tNA\n"))
+	r.SetRejectNonFinite(true)
+	r.SetNaNTokens("NA")
+	r.Next()
+	if f := r.Float64(); f != 1.5 {
+		t.Fatalf("unexpected float64: %v. Expecting %v", f, 1.5)
+	}
+	if f := r.Float32(); f != -2.25 {
+		t.Fatalf("unexpected float32: %v. Expecting %v", f, -2.25)
+	}
+	// Explicit NaN tokens are still read as NaN.
+	if f := r.Float64(); !math.IsNaN(f) {
+		t.Fatalf("unexpected float64: %v. Expecting NaN", f)
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+}
+
+func TestReaderRejectNonFiniteAllReaders(t *testing.T) {
+	for _, s := range []string{"NaN", "+Inf", "-Inf"} {
+		cols := make([]string, 600)
+		for i := range cols {
+			cols[i] = strconv.Itoa(i)
+		}
+		cols[500] = s
+		row := strings.Join(cols, "\t") + "\n"
+		for _, workers := range []int{0, 4} {
+			r := NewTSV(bytes.NewBufferString(row))
+			r.SetRejectNonFinite(true)
+			r.SetParallelColumns(workers)
+			r.Next()
+			if a := r.Float64N(600); a != nil {
+				t.Fatalf("unexpected non-nil floats for %q with %d workers", s, workers)
+			}
+			err := r.Error()
+			if err == nil {
+				t.Fatalf("expecting non-nil error for %q with %d workers", s, workers)
+			}
+			if errS := err.Error(); !strings.Contains(errS, "col #501") {
+				t.Fatalf("unexpected error: %s. Must contain %q", errS, "col #501")
+			}
+		}
+
+		r := NewTSV(bytes.NewBufferString(s + "\t1.5\n"))
+		r.SetRejectNonFinite(true)
+		r.Next()
+		if _, ok := r.NullableFloat64(); ok {
+			t.Fatalf("unexpected non-NULL value for %q", s)
+		}
+		if r.Error() == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+
+		for _, p := range []string{s + ";1.5", "1.5;" + s} {
+			r := NewTSV(bytes.NewBufferString(p + "\n"))
+			r.SetRejectNonFinite(true)
+			r.Next()
+			if _, _, err := r.Point(';'); err == nil {
+				t.Fatalf("expecting non-nil error for %q", p)
+			}
+		}
+	}
+
+	// Finite values are accepted in parallel mode.
+	cols := make([]string, 600)
+	for i := range cols {
+		cols[i] = strconv.Itoa(i)
+	}
+	r := NewTSV(bytes.NewBufferString(strings.Join(cols, "\t") + "\n"))
+	r.SetRejectNonFinite(true)
+	r.SetParallelColumns(4)
+	r.Next()
+	if a := r.Float64N(600); len(a) != 600 || a[599] != 599 {
+		t.Fatalf("unexpected floats: %v", a)
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+
+	// Non-finite values are accepted by default, NaN tokens are always accepted.
+	r = NewTSV(bytes.NewBufferString("NaN;Inf\tNA;2\n"))
+	r.SetNaNTokens("NA")
+	r.Next()
+	if a, b, err := r.Point(';'); !math.IsNaN(a) || !math.IsInf(b, 1) || err != nil {
+		t.Fatalf("unexpected point: %v;%v, err=%v. Expecting NaN;+Inf", a, b, err)
+	}
+	r.SetRejectNonFinite(true)
+	if a, b, err := r.Point(';'); !math.IsNaN(a) || b != 2 || err != nil {
+		t.Fatalf("unexpected point: %v;%v, err=%v. Expecting NaN;2", a, b, err)
+	}
+}
+
+func TestReaderBitSuccess(t *testing.T) {
+	testReaderBitSuccess(t, "0", 0, false)
+	testReaderBitSuccess(t, "1", 0, true)
+	testReaderBitSuccess(t, "5", 1, false)
+	testReaderBitSuccess(t, "5", 2, true)
+	testReaderBitSuccess(t, "-1", 63, true)
+	testReaderBitSuccess(t, "9223372036854775808", 63, true)
+	testReaderBitSuccess(t, "18446744073709551615", 0, true)
+	testReaderBitSuccess(t, "4611686018427387904", 62, true)
+}
+
+func testReaderBitSuccess(t *testing.T, s string, n uint, expected bool) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.Next()
+	v := r.Bit(n)
+	if r.Error() != nil {
+		t.Fatalf("unexpected error when reading bit %d of %q: %s", n, s, r.Error())
+	}
+	if v != expected {
+		t.Fatalf("unexpected bit %d of %q: %v. Expecting %v", n, s, v, expected)
+	}
+}
+
+func TestReaderBitFailure(t *testing.T) {
+	testReaderBitFailure(t, "", 0, "cannot parse `bit`")
+	testReaderBitFailure(t, "foo", 0, "cannot parse `bit`")
+	testReaderBitFailure(t, "1.5", 0, "cannot parse `bit`")
+	testReaderBitFailure(t, "18446744073709551616", 0, "cannot parse `bit`")
+	testReaderBitFailure(t, "1", 64, "out of range")
+}
+
+func testReaderBitFailure(t *testing.T, s string, n uint, expectedErr string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.Next()
+	if r.Bit(n) {
+		t.Fatalf("unexpected true bit %d of %q", n, s)
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error for bit %d of %q", n, s)
+	}
+	if errS := err.Error(); !strings.Contains(errS, expectedErr) {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, expectedErr)
+	}
+}
+
+func TestReaderRowFilter(t *testing.T) {
+	s := "2021-01\t1\n2021-02\t2\n# comment\n2021-01\tfoo\n2021-01\t3\n"
+	for _, src := range []io.Reader{bytes.NewBufferString(s), &slowSource{s: []byte(s)}} {
+		r := NewTSV(src)
+		r.SetCommentString("#")
+		r.SetRowFilter(func(raw []byte) bool {
+			return !bytes.HasPrefix(raw, []byte("2021-01\t"))
+		})
+		var rows []int
+		for r.Next() {
+			r.SkipCol()
+			rows = append(rows, r.Int())
+		}
+		if r.Error() != nil {
+			t.Fatalf("unexpected error: %s", r.Error())
+		}
+		if fmt.Sprint(rows) != "[2]" {
+			t.Fatalf("unexpected rows: %v. Expecting %v", rows, "[2]")
+		}
+		if n := r.SkippedRows(); n != 4 {
+			t.Fatalf("unexpected number of skipped rows: %d. Expecting %d", n, 4)
+		}
+	}
+}
+
+func TestReaderRowFilterRowNumber(t *testing.T) {
+	b := bytes.NewBufferString("skip\t1\nfoo\t2\nskip\t3\nbar\tbaz\n")
+	r := NewTSV(b)
+	r.SetRowFilter(func(raw []byte) bool {
+		return !bytes.HasPrefix(raw, []byte("skip"))
+	})
+	for r.Next() {
+		r.SkipCol()
+		r.Int()
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	// Skipped rows aren't counted.
+	if errS := err.Error(); !strings.Contains(errS, "at row #2, col #2") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "at row #2, col #2")
+	}
+
+	// Peeking must skip filtered rows too.
+	b = bytes.NewBufferString("foo\nskip\nbar\n")
+	r.Reset(b)
+	r.Next()
+	if row, ok := r.PeekRow(); !ok || string(row) != "bar" {
+		t.Fatalf("unexpected peeked row: %q. Expecting %q", row, "bar")
+	}
+	if n := r.SkippedRows(); n != 1 {
+		t.Fatalf("unexpected number of skipped rows: %d. Expecting %d", n, 1)
+	}
+}
+
+func TestReaderStringMax(t *testing.T) {
+	testReaderStringMax(t, "", 3, "")
+	testReaderStringMax(t, "foo", 3, "foo")
+	testReaderStringMax(t, "foobar", 3, "foo")
+	testReaderStringMax(t, "foobar", 0, "")
+	testReaderStringMax(t, `a\tb\tc`, 3, "a\tb")
+	testReaderStringMax(t, `\\\\\\`, 2, `\\`)
+	testReaderStringMax(t, "aé", 2, "a")
+	testReaderStringMax(t, "aé", 3, "aé")
+	testReaderStringMax(t, "€€", 5, "€")
+}
+
+func testReaderStringMax(t *testing.T, s string, max int, expected string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.Next()
+	v := r.StringMax(max)
+	if r.Error() != nil {
+		t.Fatalf("unexpected error for %q: %s", s, r.Error())
+	}
+	if v != expected {
+		t.Fatalf("unexpected string for %q: %q. Expecting %q", s, v, expected)
+	}
+}
+
+func TestReaderStringMaxStrict(t *testing.T) {
+	b := bytes.NewBufferString("foo\tfoobar\n")
+	r := NewTSV(b)
+	r.SetStringMaxStrict(true)
+	r.Next()
+	if s := r.StringMax(3); s != "foo" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "foo")
+	}
+	if s := r.StringMax(3); s != "" {
+		t.Fatalf("unexpected non-empty string: %q", s)
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := err.Error(); !strings.Contains(errS, "length 6 exceeds max length 3") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "length 6 exceeds max length 3")
+	}
+}
+
+func TestReaderStringMaxNegative(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("foo\tbar\n"))
+	r.Next()
+	if s := r.StringMax(-1); s != "" {
+		t.Fatalf("unexpected non-empty string: %q", s)
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := err.Error(); !strings.Contains(errS, "negative max length -1") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "negative max length -1")
+	}
+	if errS := err.Error(); !strings.Contains(errS, "col #1") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "col #1")
+	}
+}
+
+func TestReaderUintAutoBase(t *testing.T) {
+	b := bytes.NewBufferString("0xFF\t0b101\t0o17\t017\t42\t0xFFFFFFFF\t0xffffffffffffffff\t0x10\t1_000\n")
+	r := NewTSV(b)
+	r.SetUintAutoBase(true)
+	r.Next()
+	if n := r.Uint(); n != 255 {
+		t.Fatalf("unexpected uint: %d. Expecting %d", n, 255)
+	}
+	if n := r.Uint8(); n != 5 {
+		t.Fatalf("unexpected uint8: %d. Expecting %d", n, 5)
+	}
+	if n := r.Uint16(); n != 15 {
+		t.Fatalf("unexpected uint16: %d. Expecting %d", n, 15)
+	}
+	if n := r.Uint16(); n != 17 {
+		// The leading zero is parsed by the decimal fast path.
+		t.Fatalf("unexpected uint16: %d. Expecting %d", n, 17)
+	}
+	if n := r.Uint8(); n != 42 {
+		t.Fatalf("unexpected uint8: %d. Expecting %d", n, 42)
+	}
+	if n := r.Uint32(); n != math.MaxUint32 {
+		t.Fatalf("unexpected uint32: %d. Expecting %d", n, uint32(math.MaxUint32))
+	}
+	if n := r.Uint64(); n != math.MaxUint64 {
+		t.Fatalf("unexpected uint64: %d. Expecting %d", n, uint64(math.MaxUint64))
+	}
+	if !r.Bit(4) {
+		t.Fatalf("bit 4 of 0x10 must be set")
+	}
+	if n := r.Uint(); n != 1000 {
+		t.Fatalf("unexpected uint: %d. Expecting %d", n, 1000)
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+}
+
+func TestReaderUintAutoBaseLeadingZeros(t *testing.T) {
+	// Values exceeding the Atoi fast path mustn't be parsed as octal.
+	b := bytes.NewBufferString("09223372036854775808\t018446744073709551615\t017\t0x1\n")
+	r := NewTSV(b)
+	r.SetUintAutoBase(true)
+	r.Next()
+	if n := r.Uint64(); n != 9223372036854775808 {
+		t.Fatalf("unexpected uint64: %d. Expecting %d", n, uint64(9223372036854775808))
+	}
+	if n := r.Uint64(); n != math.MaxUint64 {
+		t.Fatalf("unexpected uint64: %d. Expecting %d", n, uint64(math.MaxUint64))
+	}
+	// NullableUint64 has no fast path.
+	if n, ok := r.NullableUint64(); n != 17 || !ok {
+		t.Fatalf("unexpected uint64: %d, ok=%v. Expecting %d", n, ok, 17)
+	}
+	if n, ok := r.NullableUint64(); n != 1 || !ok {
+		t.Fatalf("unexpected uint64: %d, ok=%v. Expecting %d", n, ok, 1)
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+
+	// Uint8 and Uint16 have their own slow path.
+	for _, read := range []func(r *Reader){
+		func(r *Reader) { r.Uint8() },
+		func(r *Reader) { r.Uint16() },
+	} {
+		r := NewTSV(bytes.NewBufferString("0_17\n"))
+		r.SetUintAutoBase(true)
+		r.Next()
+		read(r)
+		if r.Error() == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+}
+
+func TestReaderUintAutoBaseFailure(t *testing.T) {
+	testReaderUintAutoBaseFailure(t, "0x100", func(r *Reader) { r.Uint8() }, "out of range")
+	testReaderUintAutoBaseFailure(t, "0x10000", func(r *Reader) { r.Uint16() }, "out of range")
+	testReaderUintAutoBaseFailure(t, "0xfg", func(r *Reader) { r.Uint() }, "invalid syntax")
+	testReaderUintAutoBaseFailure(t, "-0x1", func(r *Reader) { r.Uint64() }, "invalid syntax")
+
+	// Base prefixes are rejected by default.
+	b := bytes.NewBufferString("0xFF\n")
+	r := NewTSV(b)
+	r.Next()
+	r.Uint()
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func testReaderUintAutoBaseFailure(t *testing.T, s string, f func(r *Reader), expectedErr string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.SetUintAutoBase(true)
+	r.Next()
+	f(r)
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error for %q", s)
+	}
+	if errS := err.Error(); !strings.Contains(errS, expectedErr) {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, expectedErr)
+	}
+}
+
+func TestReaderUnixFloatTimeSuccess(t *testing.T) {
+	testReaderUnixFloatTimeSuccess(t, "0", "1970-01-01T00:00:00Z")
+	testReaderUnixFloatTimeSuccess(t, "1614834367", "2021-03-04T05:06:07Z")
+	testReaderUnixFloatTimeSuccess(t, "1614834367.123", "2021-03-04T05:06:07.123Z")
+	testReaderUnixFloatTimeSuccess(t, "+1614834367.000000001", "2021-03-04T05:06:07.000000001Z")
+	testReaderUnixFloatTimeSuccess(t, "1614834367.1234567899", "2021-03-04T05:06:07.123456789Z")
+	testReaderUnixFloatTimeSuccess(t, "-1.5", "1969-12-31T23:59:58.5Z")
+	testReaderUnixFloatTimeSuccess(t, "-0.25", "1969-12-31T23:59:59.75Z")
+}
+
+func testReaderUnixFloatTimeSuccess(t *testing.T, s, expected string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.Next()
+	tm := r.UnixFloatTime()
+	if r.Error() != nil {
+		t.Fatalf("unexpected error for %q: %s", s, r.Error())
+	}
+	if v := tm.Format(time.RFC3339Nano); v != expected {
+		t.Fatalf("unexpected time for %q: %s. Expecting %s", s, v, expected)
+	}
+}
+
+func TestReaderUnixFloatTimeFailure(t *testing.T) {
+	testReaderUnixFloatTimeFailure(t, "")
+	testReaderUnixFloatTimeFailure(t, "-")
+	testReaderUnixFloatTimeFailure(t, "foo")
+	testReaderUnixFloatTimeFailure(t, "1.")
+	testReaderUnixFloatTimeFailure(t, ".5")
+	testReaderUnixFloatTimeFailure(t, "1.2.3")
+	testReaderUnixFloatTimeFailure(t, "1e9")
+	testReaderUnixFloatTimeFailure(t, "--1")
+	testReaderUnixFloatTimeFailure(t, "99999999999999999999.5")
+}
+
+func testReaderUnixFloatTimeFailure(t *testing.T, s string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	r.Next()
+	tm := r.UnixFloatTime()
+	if !tm.IsZero() {
+		t.Fatalf("unexpected non-zero time for %q: %s", s, tm)
+	}
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error for %q", s)
+	}
+	if errS := r.Error().Error(); !strings.Contains(errS, "cannot parse `unixfloattime`") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "cannot parse `unixfloattime`")
+	}
+}
+
+func TestReaderSetColumnNames(t *testing.T) {
+	b := bytes.NewBufferString("1\tfoo\t42\n2\tbar\t43\n")
+	r := NewTSV(b)
+	r.SetColumnNames("id", "name", "age")
+	if idx, ok := r.ColIndex("age"); !ok || idx != 2 {
+		t.Fatalf("unexpected index for %q: %d, %v. Expecting %d", "age", idx, ok, 2)
+	}
+	if _, ok := r.ColIndex("foo"); ok {
+		t.Fatalf("unexpected index for unknown column")
+	}
+	var result []string
+	for r.Next() {
+		result = append(result, r.StringByName("name")+"="+r.StringByName("age"))
+		for r.HasCols() {
+			r.SkipCol()
+		}
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+	if fmt.Sprint(result) != "[foo=42 bar=43]" {
+		t.Fatalf("unexpected result: %q. Expecting %q", result, "[foo=42 bar=43]")
+	}
+
+	// Column names survive Reset.
+	r.Reset(bytes.NewBufferString("3\tbaz\t44\n"))
+	r.Next()
+	if s := r.StringByName("name"); s != "baz" {
+		t.Fatalf("unexpected string: %q. Expecting %q", s, "baz")
+	}
+	r.StringByName("unknown")
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := err.Error(); !strings.Contains(errS, `unknown column "unknown"`) {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, `unknown column "unknown"`)
+	}
+}
+
+func TestReaderSetColumnNamesHeader(t *testing.T) {
+	b := bytes.NewBufferString("id\tname\n")
+	r := NewTSV(b)
+	r.SetColumnNames("id", "name")
+	if h := r.Header(); fmt.Sprint(h) != "[id name]" {
+		t.Fatalf("unexpected header: %q", h)
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+
+	b = bytes.NewBufferString("name\tid\n")
+	r = NewTSV(b)
+	r.SetColumnNames("id", "name")
+	if h := r.Header(); h != nil {
+		t.Fatalf("unexpected non-nil header: %q", h)
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := err.Error(); !strings.Contains(errS, "conflicts with column names") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "conflicts with column names")
+	}
+}
+
+func TestReaderTriBool(t *testing.T) {
+	b := bytes.NewBufferString("1\t0\t\tTrue\tn\n")
+	r := NewTSV(b)
+	r.Next()
+	expected := "[true/true false/true false/false true/true false/true]"
+	var result []string
+	for r.HasCols() {
+		v, known := r.TriBool()
+		result = append(result, fmt.Sprintf("%v/%v", v, known))
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+	if fmt.Sprint(result) != expected {
+		t.Fatalf("unexpected result: %q. Expecting %q", result, expected)
+	}
+}
+
+func TestReaderTriBoolCustomTokens(t *testing.T) {
+	b := bytes.NewBufferString("Y\tN\t?\tja\n")
+	r := NewTSV(b)
+	r.SetBoolTokens([]string{"Y", "ja"}, []string{"N", "nein"})
+	r.SetUnknownTokens("?")
+	r.Next()
+	if v, known := r.TriBool(); !v || !known {
+		t.Fatalf("unexpected tribool: %v, %v. Expecting true, true", v, known)
+	}
+	if v, known := r.TriBool(); v || !known {
+		t.Fatalf("unexpected tribool: %v, %v. Expecting false, true", v, known)
+	}
+	if v, known := r.TriBool(); v || known {
+		t.Fatalf("unexpected tribool: %v, %v. Expecting false, false", v, known)
+	}
+	if v, known := r.TriBool(); !v || !known {
+		t.Fatalf("unexpected tribool: %v, %v. Expecting true, true", v, known)
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+}
+
+func TestReaderTriBoolFailure(t *testing.T) {
+	testReaderTriBoolFailure(t, "2", nil)
+	testReaderTriBoolFailure(t, "maybe", nil)
+	testReaderTriBoolFailure(t, "?", nil)
+	testReaderTriBoolFailure(t, "", []string{"?"})
+}
+
+func testReaderTriBoolFailure(t *testing.T, s string, unknownTokens []string) {
+	t.Helper()
+
+	b := bytes.NewBufferString(s + "\n")
+	r := NewTSV(b)
+	if unknownTokens != nil {
+		r.SetUnknownTokens(unknownTokens...)
+	}
+	r.Next()
+	if v, known := r.TriBool(); v || known {
+		t.Fatalf("unexpected tribool for %q: %v, %v", s, v, known)
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error for %q", s)
+	}
+	if errS := err.Error(); !strings.Contains(errS, "cannot parse `tribool`") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "cannot parse `tribool`")
+	}
+}
+
+func TestReaderMaxErrorContext(t *testing.T) {
+	row := strings.Repeat("x", 300) + "\tfoo\n"
+
+	r := NewTSV(bytes.NewBufferString(row))
+	r.SetMaxErrorContext(10)
+	testReaderMaxErrorContext(t, r, strconv.Quote(strings.Repeat("x", 10))+"...")
+
+	// The default limit is 256 bytes.
+	r = NewTSV(bytes.NewBufferString(row))
+	testReaderMaxErrorContext(t, r, strconv.Quote(strings.Repeat("x", 256))+"...")
+
+	r = NewTSV(bytes.NewBufferString(row))
+	r.SetMaxErrorContext(-1)
+	testReaderMaxErrorContext(t, r, strconv.Quote(row[:len(row)-1])+":")
+}
+
+func testReaderMaxErrorContext(t *testing.T, r *Reader, expected string) {
+	t.Helper()
+
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	r.SkipCol()
+	_ = r.Int()
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if !strings.Contains(err.Error(), expected) {
+		t.Fatalf("unexpected error: %q. Expecting it to contain %q", err, expected)
+	}
+	if strings.Contains(err.Error(), expected+"x") {
+		t.Fatalf("unexpected error: %q. Expecting the row to be truncated", err)
+	}
+}
+
+func TestReaderEnumOr(t *testing.T) {
+	values := []string{"red", "green", "blue"}
+	r := NewTSV(bytes.NewBufferString("green\tpurple\t\tblue\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	testReaderEnumOr(t, r, values, 1)
+	testReaderEnumOr(t, r, values, -1)
+	testReaderEnumOr(t, r, values, -1)
+	testReaderEnumOr(t, r, values, 2)
+
+	// Missing column is an error.
+	if n := r.EnumOr(values, -1); n != -1 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, -1)
+	}
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func testReaderEnumOr(t *testing.T, r *Reader, values []string, expected int) {
+	t.Helper()
+
+	n := r.EnumOr(values, -1)
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n != expected {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, expected)
+	}
+}
+
+func TestReaderUnderlying(t *testing.T) {
+	data := "a\t1\nb\t2\n" + "-- footer --\nfoo bar"
+
+	for _, peek := range []bool{false, true} {
+		r := NewTSV(&slowSource{s: []byte(data)})
+		for i := 0; i < 2; i++ {
+			if !r.Next() {
+				t.Fatalf("cannot find the next row: %v", r.Error())
+			}
+			r.SkipCol()
+			r.SkipCol()
+		}
+		if peek {
+			if _, ok := r.PeekRow(); !ok {
+				t.Fatalf("cannot peek the next row: %v", r.Error())
+			}
+		}
+		tail, err := io.ReadAll(r.Underlying())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(tail) != "-- footer --\nfoo bar" {
+			t.Fatalf("unexpected tail: %q. Expecting %q", tail, "-- footer --\nfoo bar")
+		}
+	}
+}
+
+func TestReaderDurationSeconds(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("1.5\t-0.25\t0\t3\t1e-9\t0.0000000004\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	testReaderDurationSeconds(t, r, 1500*time.Millisecond)
+	testReaderDurationSeconds(t, r, -250*time.Millisecond)
+	testReaderDurationSeconds(t, r, 0)
+	testReaderDurationSeconds(t, r, 3*time.Second)
+	testReaderDurationSeconds(t, r, 1)
+	testReaderDurationSeconds(t, r, 0)
+
+	for _, s := range []string{"", "foo", "1s", "NaN", "+Inf", "1e20"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		_ = r.DurationSeconds()
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func testReaderDurationSeconds(t *testing.T, r *Reader, expected time.Duration) {
+	t.Helper()
+
+	d := r.DurationSeconds()
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d != expected {
+		t.Fatalf("unexpected duration: %s. Expecting %s", d, expected)
+	}
+}
+
+func TestReaderResetKeepBuffers(t *testing.T) {
+	// Rows longer than the read buffer go through the scratch buffer.
+	data := []byte(strings.Repeat("x", 10<<10) + "\t" + strings.Repeat("y", 5<<10) + "\n")
+	br := bytes.NewReader(data)
+	r := NewTSV(br)
+	read := func() {
+		br.Reset(data)
+		r.ResetKeepBuffers(br)
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if n := len(r.Bytes()); n != 10<<10 {
+			t.Fatalf("unexpected column length: %d. Expecting %d", n, 10<<10)
+		}
+		if n := len(r.Bytes()); n != 5<<10 {
+			t.Fatalf("unexpected column length: %d. Expecting %d", n, 5<<10)
+		}
+		if r.Next() {
+			t.Fatalf("unexpected next row")
+		}
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	read()
+
+	if n := testing.AllocsPerRun(10, read); n != 0 {
+		t.Fatalf("unexpected number of allocations: %v. Expecting 0", n)
+	}
+}
+
+func TestReaderISODuration(t *testing.T) {
+	testReaderISODuration(t, "PT1H30M", time.Hour+30*time.Minute)
+	testReaderISODuration(t, "P1DT2H", 26*time.Hour)
+	testReaderISODuration(t, "P2W", 14*24*time.Hour)
+	testReaderISODuration(t, "PT0.5S", 500*time.Millisecond)
+	testReaderISODuration(t, "PT1,000000001S", time.Second+1)
+	testReaderISODuration(t, "-PT90S", -90*time.Second)
+	testReaderISODuration(t, "P0D", 0)
+	testReaderISODuration(t, "PT1M", time.Minute)
+
+	for _, s := range []string{"", "1H", "P", "PT", "P1H", "PT1D", "P1Y", "P1M", "PT1.5M", "PT1.5S2M", "PT1", "PTH", "P1DT2HT3M", "PT9999999999999H"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		_ = r.ISODuration()
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func TestReaderISODurationApprox(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("P1Y2M3DT4M\n"))
+	r.SetISODurationApprox(true)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	d := r.ISODuration()
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := (365+60+3)*24*time.Hour + 4*time.Minute
+	if d != expected {
+		t.Fatalf("unexpected duration: %s. Expecting %s", d, expected)
+	}
+}
+
+func testReaderISODuration(t *testing.T, s string, expected time.Duration) {
+	t.Helper()
+
+	r := NewTSV(bytes.NewBufferString(s + "\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	d := r.ISODuration()
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d != expected {
+		t.Fatalf("unexpected duration for %q: %s. Expecting %s", s, d, expected)
+	}
+}
+
+func TestReaderMissingColumnValue(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("a\t1\tx\nb\\n\n\n"))
+	r.SetMissingColumnValue([]byte(`0\t`))
+
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	testReaderMissingColumnValue(t, r, "a", "1", "x")
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	testReaderMissingColumnValue(t, r, "b\n", `0\t`, `0\t`)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	testReaderMissingColumnValue(t, r, "", `0\t`, `0\t`)
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+
+	r = NewTSV(bytes.NewBufferString("a\n"))
+	r.SetMissingColumnValue([]byte{})
+	r.SetMissingColumnValue(nil)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	r.SkipCol()
+	r.SkipCol()
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func testReaderMissingColumnValue(t *testing.T, r *Reader, expected ...string) {
+	t.Helper()
+
+	for _, s := range expected {
+		if v := r.String(); v != s {
+			t.Fatalf("unexpected value: %q. Expecting %q", v, s)
+		}
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReaderImpliedDecimal(t *testing.T) {
+	testReaderImpliedDecimal(t, "12345", 2, 12345)
+	testReaderImpliedDecimal(t, "-12345", 2, -12345)
+	testReaderImpliedDecimal(t, "123.45", 2, 12345)
+	testReaderImpliedDecimal(t, "123.4", 2, 12340)
+	testReaderImpliedDecimal(t, "123.", 2, 12300)
+	testReaderImpliedDecimal(t, "+.5", 3, 500)
+	testReaderImpliedDecimal(t, "0001234{", 2, 12340)
+	testReaderImpliedDecimal(t, "0001234A", 2, 12341)
+	testReaderImpliedDecimal(t, "0001234I", 2, 12349)
+	testReaderImpliedDecimal(t, "0001234}", 2, -12340)
+	testReaderImpliedDecimal(t, "0001234J", 2, -12341)
+	testReaderImpliedDecimal(t, "0001234R", 2, -12349)
+	testReaderImpliedDecimal(t, "R", 0, -9)
+	testReaderImpliedDecimal(t, "9223372036854775807", 0, math.MaxInt64)
+	testReaderImpliedDecimal(t, "-9223372036854775808", 0, math.MinInt64)
+
+	for _, s := range []string{"", "-", ".", "1.2.3", "12a", "1.234", "-123{", "S", "9223372036854775808", "92233720368547758.08"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if _, err := r.ImpliedDecimal(2); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func testReaderImpliedDecimal(t *testing.T, s string, fracDigits int, expected int64) {
+	t.Helper()
+
+	r := NewTSV(bytes.NewBufferString(s + "\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	n, err := r.ImpliedDecimal(fracDigits)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n != expected {
+		t.Fatalf("unexpected value for %q: %d. Expecting %d", s, n, expected)
+	}
+}
+
+func TestReaderPrefetch(t *testing.T) {
+	var bb bytes.Buffer
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&bb, "%d\tfoo\\tbar %d\n", i, i)
+	}
+	data := bb.Bytes()
+
+	for _, buffers := range []int{1, 2, 4} {
+		r := NewTSV(&slowSource{s: data})
+		r.SetPrefetch(buffers)
+		for i := 0; i < 1000; i++ {
+			if !r.Next() {
+				t.Fatalf("cannot find row #%d: %v", i+1, r.Error())
+			}
+			if n := r.Int(); n != i {
+				t.Fatalf("unexpected int: %d. Expecting %d", n, i)
+			}
+			if i%10 == 0 {
+				if _, ok := r.PeekRow(); !ok {
+					t.Fatalf("cannot peek row #%d: %v", i+2, r.Error())
+				}
+			}
+			expected := fmt.Sprintf("foo\tbar %d", i)
+			if s := r.String(); s != expected {
+				t.Fatalf("unexpected string: %q. Expecting %q", s, expected)
+			}
+		}
+		if r.Next() {
+			t.Fatalf("unexpected next row")
+		}
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		// Abandon the stream in the middle and reuse the reader.
+		br := bytes.NewReader(data)
+		r.Reset(br)
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		r.Reset(&slowSource{s: data[:len(data)/2]})
+		tail, err := io.ReadAll(r.Underlying())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(tail) != string(data[:len(data)/2]) {
+			t.Fatalf("unexpected tail: %q. Expecting %q", tail, data[:len(data)/2])
+		}
+	}
+}
+
+func TestReaderPrefetchUnderlying(t *testing.T) {
+	data := strings.Repeat("a\t1\n", 5000) + "-- footer --\n" + strings.Repeat("x", 20<<10)
+	r := NewTSV(strings.NewReader(data))
+	r.SetPrefetch(3)
+	for i := 0; i < 5000; i++ {
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		r.SkipCol()
+		r.SkipCol()
+	}
+	tail, err := io.ReadAll(r.Underlying())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(tail) != data[5000*4:] {
+		t.Fatalf("unexpected tail of %d bytes. Expecting %d bytes", len(tail), len(data)-5000*4)
+	}
+}
+
+func TestReaderFloat64Or(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("1.5\t\t\\N\tfoo\tNaN\t-Inf\t1e400\tn/a\t-2\n"))
+	r.SetNaNTokens("n/a")
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []float64{1.5, -1, -1, -1, -1, -1, -1, -1, -2} {
+		f := r.Float64Or(-1)
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if f != expected {
+			t.Fatalf("unexpected value: %v. Expecting %v", f, expected)
+		}
+	}
+
+	_ = r.Float64Or(-1)
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderWriteColumnTo(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("foo\\tbar\t\tbaz\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	var bb bytes.Buffer
+	for _, expected := range []string{"foo\tbar", "", "baz"} {
+		bb.Reset()
+		n, err := r.WriteColumnTo(&bb)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if n != int64(len(expected)) {
+			t.Fatalf("unexpected number of bytes written: %d. Expecting %d", n, len(expected))
+		}
+		if bb.String() != expected {
+			t.Fatalf("unexpected column: %q. Expecting %q", bb.String(), expected)
+		}
+	}
+	if _, err := r.WriteColumnTo(&bb); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderStrictDates(t *testing.T) {
+	// Out-of-range values are normalized by default.
+	r := NewTSV(bytes.NewBufferString("2021-02-30\t2021-13-01\t2021-01-01 24:00:00\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []time.Time{
+		time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if d := r.Date(); !d.Equal(expected) {
+			t.Fatalf("unexpected date: %s. Expecting %s", d, expected)
+		}
+	}
+	expected := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+	if dt := r.DateTime(); !dt.Equal(expected) {
+		t.Fatalf("unexpected datetime: %s. Expecting %s", dt, expected)
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	r = NewTSV(bytes.NewBufferString("2020-02-29\t0000-00-00\t2021-12-31 23:59:59\t0000-00-00 00:00:00\n"))
+	r.SetStrictDates(true)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if d := r.Date(); !d.Equal(time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected date: %s", d)
+	}
+	if d := r.Date(); !d.IsZero() {
+		t.Fatalf("unexpected date: %s. Expecting zero time", d)
+	}
+	if dt := r.DateTime(); !dt.Equal(time.Date(2021, 12, 31, 23, 59, 59, 0, time.UTC)) {
+		t.Fatalf("unexpected datetime: %s", dt)
+	}
+	if dt := r.DateTime(); !dt.IsZero() {
+		t.Fatalf("unexpected datetime: %s. Expecting zero time", dt)
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, s := range []string{"2021-02-29", "2021-02-30", "2021-04-31", "2021-13-01", "2021-00-10", "2021-01-00", "2021-01-32"} {
+		testReaderStrictDatesError(t, s, (*Reader).Date)
+		testReaderStrictDatesError(t, s+" 00:00:00", (*Reader).DateTime)
+	}
+	for _, s := range []string{"2021-01-01 24:00:00", "2021-01-01 12:60:00", "2021-01-01 12:00:60"} {
+		testReaderStrictDatesError(t, s, (*Reader).DateTime)
+	}
+}
+
+func testReaderStrictDatesError(t *testing.T, s string, read func(*Reader) time.Time) {
+	t.Helper()
+
+	r := NewTSV(bytes.NewBufferString(s + "\n"))
+	r.SetStrictDates(true)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	read(r)
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error for %q", s)
+	}
+}
+
+func TestReaderURL(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("https://example.com/a?b=c#d\t/relative\t%zz\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"https://example.com/a?b=c#d", "/relative"} {
+		u := r.URL()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if u.String() != expected {
+			t.Fatalf("unexpected url: %q. Expecting %q", u, expected)
+		}
+	}
+	if u := r.URL(); u != nil {
+		t.Fatalf("unexpected url: %q. Expecting nil", u)
+	}
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderHeaderRowIndex(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("Sales report\n\ngenerated\tby\tfoo\tbar\nid\tname\n1\tfoo\n"))
+	r.SetHeaderRowIndex(3)
+	header := r.Header()
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Join(header, ",") != "id,name" {
+		t.Fatalf("unexpected header: %q. Expecting %q", header, []string{"id", "name"})
+	}
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if n := r.Int(); n != 1 {
+		t.Fatalf("unexpected id: %d. Expecting 1", n)
+	}
+	if s := r.String(); s != "foo" {
+		t.Fatalf("unexpected name: %q. Expecting %q", s, "foo")
+	}
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+
+	r = NewTSV(bytes.NewBufferString("banner\n"))
+	r.SetHeaderRowIndex(3)
+	if err := r.ExpectHeader("id"); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderHeaderRowIndexWidth(t *testing.T) {
+	for _, setWidth := range []func(r *Reader){
+		func(r *Reader) { r.SetExpectedCols(3) },
+		func(r *Reader) { r.ExpectHeaderWidth() },
+	} {
+		r := NewCSV(bytes.NewBufferString("My Report\na,b,c\n1,2,3\n4,5\n"))
+		r.SetHeaderRowIndex(1)
+		setWidth(r)
+		if err := r.ExpectHeader("a", "b", "c"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if cols := r.RemainingCols(); strings.Join(cols, ",") != "1,2,3" {
+			t.Fatalf("unexpected columns: %q. Expecting %q", cols, "1,2,3")
+		}
+
+		// The width check is restored after skipping banner rows.
+		if r.Next() {
+			t.Fatalf("unexpected next row")
+		}
+		if r.Error() == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+}
+
+func TestReaderTimeOfDay(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("14:30:05\t00:00:00\t24:00:00\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []time.Duration{
+		14*time.Hour + 30*time.Minute + 5*time.Second,
+		0,
+		24 * time.Hour,
+	} {
+		d := r.TimeOfDay()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if d != expected {
+			t.Fatalf("unexpected time of day: %s. Expecting %s", d, expected)
+		}
+	}
+
+	for _, s := range []string{"", "14:30", "14-30-00", "1:30:00", "aa:00:00", "2021-01-01 00:00:00"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		r.TimeOfDay()
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+	for _, s := range []string{"24:00:00", "23:60:00", "23:59:60"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		r.SetStrictDates(true)
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		r.TimeOfDay()
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func TestReaderParallelColumns(t *testing.T) {
+	const cols = 2000
+	var bb bytes.Buffer
+	for i := 0; i < cols; i++ {
+		if i > 0 {
+			bb.WriteByte('\t')
+		}
+		fmt.Fprintf(&bb, "%d", i)
+	}
+	bb.WriteByte('\n')
+	row := bb.String()
+
+	r := NewTSV(strings.NewReader(row + row))
+	r.SetParallelColumns(4)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	a := r.IntN(cols)
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i, n := range a {
+		if n != i {
+			t.Fatalf("unexpected int at col #%d: %d. Expecting %d", i+1, n, i)
+		}
+	}
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	f := r.Float64N(cols)
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i, v := range f {
+		if v != float64(i) {
+			t.Fatalf("unexpected float64 at col #%d: %v. Expecting %d", i+1, v, i)
+		}
+	}
+
+	// The first invalid column must be reported.
+	bad := strings.Replace(strings.Replace(row, "\t1500\t", "\tfoo\t", 1), "\t700\t", "\tbar\t", 1)
+	r = NewTSV(strings.NewReader(bad))
+	r.SetParallelColumns(4)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if a := r.IntN(cols); a != nil {
+		t.Fatalf("unexpected non-nil result")
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if !strings.Contains(err.Error(), "row #1, col #701") {
+		t.Fatalf("unexpected error: %q. Expecting it to contain %q", err, "row #1, col #701")
+	}
+
+	// Short rows result in an error.
+	r = NewTSV(strings.NewReader(row))
+	r.SetParallelColumns(4)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if a := r.IntN(cols + 1); a != nil {
+		t.Fatalf("unexpected non-nil result")
+	}
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderRowMap(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("id\tname\tid\n1\tfoo\\tbar\t2\n3\n4\tbaz\t5\t6\n"))
+	if r.Header() == nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	testReaderRowMap(t, r, map[string]string{"id": "1", "name": "foo\tbar"})
+
+	// Missing columns are omitted.
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	testReaderRowMap(t, r, map[string]string{"id": "3"})
+
+	// Extra columns result in an error.
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if _, err := r.RowMap(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	// Header is required.
+	r = NewTSV(bytes.NewBufferString("1\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if _, err := r.RowMap(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func testReaderRowMap(t *testing.T, r *Reader, expected map[string]string) {
+	t.Helper()
+
+	m, err := r.RowMap()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(m) != len(expected) {
+		t.Fatalf("unexpected row map: %q. Expecting %q", m, expected)
+	}
+	for k, v := range expected {
+		if m[k] != v {
+			t.Fatalf("unexpected row map: %q. Expecting %q", m, expected)
+		}
+	}
+}
+
+func TestReaderQuoting(t *testing.T) {
+	data := "\"Smith, John\",42,\"a \"\"b\"\", c\"\n" +
+		"\"multi\nline\",,\"\"\n" +
+		"plain,5\" inch,\"x\\ty\"\n" +
+		"\"\",\"\"\"\",end\n"
+	expected := [][]string{
+		{"Smith, John", "42", "a \"b\", c"},
+		{"multi\nline", "", ""},
+		{"plain", "5\" inch", "x\\ty"},
+		{"", "\"", "end"},
+	}
+	testReaderQuoting(t, strings.NewReader(data), expected)
+	testReaderQuoting(t, &slowSource{s: []byte(data)}, expected)
+
+	// Quoted newlines and quotes split across buffer fills.
+	testReaderQuoting(t, &chunkSource{chunks: []string{"\"multi", "\nline\",\"a \"", "\"b\"", "\"\"\n\"x\"", ",y\n"}}, [][]string{
+		{"multi\nline", "a \"b\""},
+		{"x", "y"},
+	})
+}
+
+func testReaderQuoting(t *testing.T, src io.Reader, expected [][]string) {
+	t.Helper()
+
+	r := NewCSV(src)
+	r.SetQuoting('"')
+	for i, row := range expected {
+		if !r.Next() {
+			t.Fatalf("cannot find row #%d: %v", i+1, r.Error())
+		}
+		for j, s := range row {
+			v := r.String()
+			if err := r.Error(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if v != s {
+				t.Fatalf("unexpected value at row #%d, col #%d: %q. Expecting %q", i+1, j+1, v, s)
+			}
+		}
+		if r.HasCols() {
+			t.Fatalf("unexpected unread columns at row #%d", i+1)
+		}
+	}
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReaderQuotingError(t *testing.T) {
+	testReaderQuotingError(t, "\"foo\"bar,1\n")
+	testReaderQuotingError(t, "1,\"foo\n")
+	testReaderQuotingError(t, "1,\"foo\"\"\n")
+
+	// Bad rows may be skipped.
+	r := NewCSV(bytes.NewBufferString("\"a\"b,\"c\"\n\"d\",e\n"))
+	r.SetQuoting('"')
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	r.SkipCol()
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if string(r.LastBadRow()) != "\"a\"b,\"c\"" {
+		t.Fatalf("unexpected bad row: %q", r.LastBadRow())
+	}
+	r.ResetError()
+	for r.HasCols() {
+		r.SkipCol()
+	}
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := r.String() + r.String(); s != "de" {
+		t.Fatalf("unexpected values: %q. Expecting %q", s, "de")
+	}
+}
+
+func testReaderQuotingError(t *testing.T, s string) {
+	t.Helper()
+
+	r := NewCSV(bytes.NewBufferString(s))
+	r.SetQuoting('"')
+	for r.Next() {
+		for r.HasCols() {
+			r.SkipCol()
+		}
+	}
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error for %q", s)
+	}
+}
+
+func TestReaderLazyQuotes(t *testing.T) {
+	data := "\"a\"b\",c\n" +
+		"\"x \"y\" z\",\"a\"b\"\"c\"\n" +
+		"plain,\"open\nmore\n"
+	expected := [][]string{
+		{"a\"b", "c"},
+		{"x \"y\" z", "a\"b\"c"},
+		{"plain", "open\nmore"},
+	}
+	testReaderLazyQuotes(t, strings.NewReader(data), expected)
+	testReaderLazyQuotes(t, &slowSource{s: []byte(data)}, expected)
+
+	// Lazy quotes are rejected by default.
+	r := NewCSV(bytes.NewBufferString("\"a\"b\",c\n"))
+	r.SetQuoting('"')
+	r.Next()
+	r.SkipCol()
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func testReaderLazyQuotes(t *testing.T, src io.Reader, expected [][]string) {
+	t.Helper()
+
+	r := NewCSV(src)
+	r.SetQuoting('"')
+	r.SetLazyQuotes(true)
+	for i, row := range expected {
+		if !r.Next() {
+			t.Fatalf("cannot find row #%d: %v", i+1, r.Error())
+		}
+		if n := r.NumCols(); n != len(row) {
+			t.Fatalf("unexpected number of columns at row #%d: %d. Expecting %d", i+1, n, len(row))
+		}
+		for j, s := range row {
+			v := r.String()
+			if err := r.Error(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if v != s {
+				t.Fatalf("unexpected value at row #%d, col #%d: %q. Expecting %q", i+1, j+1, v, s)
+			}
+		}
+		if r.HasCols() {
+			t.Fatalf("unexpected unread columns at row #%d", i+1)
+		}
+	}
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReaderQuotingRawLine(t *testing.T) {
+	r := NewCSV(bytes.NewBufferString("\"a \"\"b\"\"\",c\n"))
+	r.SetQuoting('"')
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := r.String(); s != "a \"b\"" {
+		t.Fatalf("unexpected value: %q. Expecting %q", s, "a \"b\"")
+	}
+	if s := string(r.RawLine()); s != "\"a \"\"b\"\"\",c\n" {
+		t.Fatalf("unexpected raw line: %q", s)
+	}
+	r.SkipCol()
+}
+
+func TestReaderFieldQuoted(t *testing.T) {
+	r := NewCSV(bytes.NewBufferString("\"a,\"\"b\"\"\",c,\"\",\n"))
+	r.SetQuoting('"')
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []struct {
+		s      string
+		quoted bool
+	}{
+		{"a,\"b\"", true},
+		{"c", false},
+		{"", true},
+		{"", false},
+	} {
+		b, quoted := r.FieldQuoted()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(b) != expected.s || quoted != expected.quoted {
+			t.Fatalf("unexpected value: %q, quoted=%v. Expecting %q, quoted=%v", b, quoted, expected.s, expected.quoted)
+		}
+	}
+
+	if b, quoted := r.FieldQuoted(); b != nil || quoted {
+		t.Fatalf("unexpected value: %q, quoted=%v. Expecting nil, quoted=false", b, quoted)
+	}
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	// Columns are never quoted without SetQuoting.
+	r = NewCSV(bytes.NewBufferString("\"a\"\n"))
+	r.Next()
+	if b, quoted := r.FieldQuoted(); string(b) != "\"a\"" || quoted {
+		t.Fatalf("unexpected value: %q, quoted=%v. Expecting %q, quoted=false", b, quoted, "\"a\"")
+	}
+}
+
+func TestReaderBool(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("1\t0\ttrue\tFALSE\tT\tf\tYes\tnO\ty\tN\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for i, expected := range []bool{true, false, true, false, true, false, true, false, true, false} {
+		v := r.Bool()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if v != expected {
+			t.Fatalf("unexpected value at col #%d: %v. Expecting %v", i+1, v, expected)
+		}
+	}
+
+	for _, s := range []string{"", "2", "truee", "off"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if r.Bool() {
+			t.Fatalf("unexpected true value for %q", s)
+		}
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+
+	r = NewTSV(bytes.NewBufferString("Ja\tnein\n"))
+	r.SetBoolTokens([]string{"ja"}, []string{"nein"})
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if v := ReadAt[bool](r, 0); !v {
+		t.Fatalf("unexpected value: %v. Expecting true", v)
+	}
+	if !r.Bool() || r.Bool() {
+		t.Fatalf("unexpected values")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReaderDateLayout(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("04/03/2021\t31/12/1999\t00/00/0000\t0000-00-00\n2021-03-04\n"))
+	r.SetDateLayout("02/01/2006")
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []time.Time{
+		time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC),
+		time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC),
+		zeroTime,
+		zeroTime,
+	} {
+		d := r.Date()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !d.Equal(expected) {
+			t.Fatalf("unexpected date: %s. Expecting %s", d, expected)
+		}
+	}
+
+	// The default fast parser is restored with an empty layout.
+	r.SetDateLayout("")
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if d := r.Date(); !d.Equal(time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected date: %s", d)
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, layout := range []string{"", "02/01/2006"} {
+		r := NewTSV(bytes.NewBufferString("31/02/2021\n"))
+		r.SetDateLayout(layout)
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		r.Date()
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for layout %q", layout)
+		}
+	}
+}
+
+func TestReaderUnixTime(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("1614834367\t-1\t0\t1614834367123\t-1\t-1500\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	testReaderUnixTime(t, r.UnixTime(), time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	testReaderUnixTime(t, r.UnixTime(), time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC))
+	testReaderUnixTime(t, r.UnixTime(), time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC))
+	testReaderUnixTime(t, r.UnixMilliTime(), time.Date(2021, 3, 4, 5, 6, 7, 123e6, time.UTC))
+	testReaderUnixTime(t, r.UnixMilliTime(), time.Date(1969, 12, 31, 23, 59, 59, 999e6, time.UTC))
+	testReaderUnixTime(t, r.UnixMilliTime(), time.Date(1969, 12, 31, 23, 59, 58, 500e6, time.UTC))
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, s := range []string{"", "1.5", "abc", "99999999999999999999"} {
+		for _, read := range []func(*Reader) time.Time{(*Reader).UnixTime, (*Reader).UnixMilliTime} {
+			r := NewTSV(bytes.NewBufferString(s + "\n"))
+			if !r.Next() {
+				t.Fatalf("cannot find the next row: %v", r.Error())
+			}
+			read(r)
+			if err := r.Error(); err == nil {
+				t.Fatalf("expecting non-nil error for %q", s)
+			}
+		}
+	}
+}
+
+func testReaderUnixTime(t *testing.T, tm, expected time.Time) {
+	t.Helper()
+
+	if !tm.Equal(expected) {
+		t.Fatalf("unexpected time: %s. Expecting %s", tm, expected)
+	}
+}
+
+func TestReaderRemainingCols(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("1\tfoo\\tbar\t\tbaz\n2\n3\ta\tb\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	r.SkipCol()
+	testReaderRemainingCols(t, r.RemainingCols(), "foo\tbar", "", "baz")
+	if r.HasCols() {
+		t.Fatalf("unexpected unread columns")
+	}
+
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	r.SkipCol()
+	testReaderRemainingCols(t, r.RemainingCols())
+
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	var a []string
+	for _, b := range r.RemainingBytes() {
+		a = append(a, string(b))
+	}
+	testReaderRemainingCols(t, a, "3", "a", "b")
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func testReaderRemainingCols(t *testing.T, a []string, expected ...string) {
+	t.Helper()
+
+	if strings.Join(a, ",") != strings.Join(expected, ",") || len(a) != len(expected) {
+		t.Fatalf("unexpected columns: %q. Expecting %q", a, expected)
+	}
+}
+
+func TestReaderReadHeader(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("#v1\tid\tname\tid\n1\tfoo\\tbar\t2\n3\tbaz\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := r.String(); s != "#v1" {
+		t.Fatalf("unexpected version: %q. Expecting %q", s, "#v1")
+	}
+	header := r.ReadHeader()
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Join(header, ",") != "id,name,id" {
+		t.Fatalf("unexpected header: %q", header)
+	}
+
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	testReaderColByName(t, r, "name", "foo\tbar")
+	testReaderColByName(t, r, "id", "1")
+	testReaderColByName(t, r, "name", "foo\tbar")
+	if r.HasCols() {
+		t.Fatalf("unexpected unread columns")
+	}
+
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	testReaderColByName(t, r, "name", "baz")
+	if b := r.ColByName("foo"); b != nil {
+		t.Fatalf("unexpected value for unknown column: %q", b)
+	}
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	r = NewTSV(bytes.NewBufferString("1\n"))
+	if h := r.ReadHeader(); h != nil {
+		t.Fatalf("unexpected header before Next call: %q", h)
+	}
+}
+
+func testReaderColByName(t *testing.T, r *Reader, name, expected string) {
+	t.Helper()
+
+	b := r.ColByName(name)
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(b) != expected {
+		t.Fatalf("unexpected value for %q: %q. Expecting %q", name, b, expected)
+	}
+}
+
+func TestReaderNewBytes(t *testing.T) {
+	data := "a\\tb\t1\n\nc\t2\n"
+	for _, r := range []*Reader{NewBytes('\t', []byte(data)), NewString('\t', data)} {
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if s := r.String(); s != "a\tb" {
+			t.Fatalf("unexpected value: %q. Expecting %q", s, "a\tb")
+		}
+		if n := r.Int(); n != 1 {
+			t.Fatalf("unexpected value: %d. Expecting 1", n)
+		}
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if r.HasCols() {
+			t.Fatalf("unexpected columns in empty row")
+		}
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if s := r.String(); s != "c" {
+			t.Fatalf("unexpected value: %q. Expecting %q", s, "c")
+		}
+		if n := r.Int(); n != 2 {
+			t.Fatalf("unexpected value: %d. Expecting 2", n)
+		}
+		if r.Next() {
+			t.Fatalf("unexpected next row")
+		}
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	r := NewBytes(',', []byte("a,b"))
+	if r.Next() {
+		t.Fatalf("unexpected row without newline")
+	}
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderBufferSize(t *testing.T) {
+	var bb bytes.Buffer
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&bb, "%d\t%s\n", i, strings.Repeat("x", i*10))
+	}
+	data := bb.Bytes()
+
+	for _, size := range []int{1, 64, 100, 64 << 10} {
+		r := NewTSV(&slowSource{s: data})
+		r.SetBufferSize(size)
+		if n := len(r.readBuf()); n < size || n < minBufferSize {
+			t.Fatalf("unexpected buffer size: %d for %d", n, size)
+		}
+		for i := 0; r.Next(); i++ {
+			if n := r.Int(); n != i {
+				t.Fatalf("unexpected int: %d. Expecting %d", n, i)
+			}
+			if s := r.String(); len(s) != i*10 {
+				t.Fatalf("unexpected string length: %d. Expecting %d", len(s), i*10)
+			}
+		}
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	r := NewTSV(nil)
+	r.SetBufferSize(100)
+	r.SetBufferSize(0)
+	if n := len(r.readBuf()); n != len(r.rBuf) {
+		t.Fatalf("unexpected buffer size: %d. Expecting %d", n, len(r.rBuf))
+	}
+}
+
+func TestReaderTrimCR(t *testing.T) {
+	testReaderTrimCR(t, "a,1\r\nb,2\r\n", "a:1,b:2,")
+	testReaderTrimCR(t, "a,1\r\nb,2\nc,3\r\n", "a:1,b:2,c:3,")
+	testReaderTrimCR(t, "a\rb,1\r\n\r\n", "a\rb:1,")
+	testReaderTrimCR(t, "a\r,1\n", "a\r:1,")
+
+	// The carriage return is kept without SetTrimCR.
+	r := NewCSV(bytes.NewBufferString("a,1\r\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	r.SkipCol()
+	if s := r.String(); s != "1\r" {
+		t.Fatalf("unexpected value: %q. Expecting %q", s, "1\r")
+	}
+
+	// RawLine includes the trimmed carriage return.
+	r = NewCSV(bytes.NewBufferString("a,1\r\n"))
+	r.SetTrimCR(true)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := string(r.RawLine()); s != "a,1\r\n" {
+		t.Fatalf("unexpected raw line: %q. Expecting %q", s, "a,1\r\n")
+	}
+	r.SkipCol()
+	r.SkipCol()
+}
+
+func testReaderTrimCR(t *testing.T, s, expected string) {
+	t.Helper()
+
+	r := NewCSV(&slowSource{s: []byte(s)})
+	r.SetTrimCR(true)
+	var result string
+	for r.Next() {
+		if !r.HasCols() {
+			continue
+		}
+		result += r.String() + ":" + strconv.Itoa(r.Int()) + ","
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result != expected {
+		t.Fatalf("unexpected result: %q. Expecting %q", result, expected)
+	}
+}
+
+func TestReaderComment(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("# header\nfoo\t#1\n#bar\t2\nbaz\t3\n"))
+	r.SetComment('#')
+	var result string
+	for r.Next() {
+		result += fmt.Sprintf("%s:%s@%s,", r.String(), r.String(), r.At())
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := "foo:#1@row #1, col #2,baz:3@row #2, col #2,"; result != expected {
+		t.Fatalf("unexpected result: %q. Expecting %q", result, expected)
+	}
+
+	r = NewTSV(bytes.NewBufferString("#foo\t1\n"))
+	r.SetComment('#')
+	r.SetComment(0)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := r.String(); s != "#foo" {
+		t.Fatalf("unexpected value: %q. Expecting %q", s, "#foo")
+	}
+	r.SkipCol()
+}
+
+func TestReaderSkipEmptyLines(t *testing.T) {
+	testReaderSkipEmptyLines(t, "\n\nfoo\t1\n", "foo:1@row #1,")
+	testReaderSkipEmptyLines(t, "foo\t1\n\n\nbar\t2\n", "foo:1@row #1,bar:2@row #2,")
+	testReaderSkipEmptyLines(t, "foo\t1\n\n", "foo:1@row #1,")
+	testReaderSkipEmptyLines(t, "\r\nfoo\t1\r\n\r\n", "foo:1@row #1,")
+	testReaderSkipEmptyLines(t, "\n\n", "")
+
+	// Lines with empty columns aren't empty.
+	r := NewTSV(bytes.NewBufferString("\t\n"))
+	r.SetSkipEmptyLines(true)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := r.String() + r.String(); s != "" {
+		t.Fatalf("unexpected values: %q", s)
+	}
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+}
+
+func testReaderSkipEmptyLines(t *testing.T, s, expected string) {
+	t.Helper()
+
+	r := NewTSV(bytes.NewBufferString(s))
+	r.SetSkipEmptyLines(true)
+	r.SetTrimCR(true)
+	var result string
+	for r.Next() {
+		result += fmt.Sprintf("%s:%d@row #%d,", r.String(), r.Int(), r.row)
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result != expected {
+		t.Fatalf("unexpected result: %q. Expecting %q", result, expected)
+	}
+}
+
+func TestReaderBigInt(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("1234567890123456789012345678901234567890\t-42\t+7\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"1234567890123456789012345678901234567890", "-42", "7"} {
+		n := r.BigInt()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if n.String() != expected {
+			t.Fatalf("unexpected value: %s. Expecting %s", n, expected)
+		}
+	}
+
+	for _, s := range []string{"", "-", "1.5", "0x10", "12a"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if n := r.BigInt(); n != nil {
+			t.Fatalf("unexpected value for %q: %s", s, n)
+		}
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func TestReaderBigFloat(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("1234567890123456789012345678901234567890.5\t-1.25\t+1e3\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"1234567890123456789012345678901234567890.5", "-1.25", "1000"} {
+		f := r.BigFloat()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if s := f.Text('f', -1); s != expected {
+			t.Fatalf("unexpected value: %s. Expecting %s", s, expected)
+		}
+	}
+
+	for _, s := range []string{"", "-", "1.5.5", "foo"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if f := r.BigFloat(); f != nil {
+			t.Fatalf("unexpected value for %q: %s", s, f)
+		}
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func TestReaderNullable(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("\\N\t\t42\t-1\t7\t1.5\tyes\tfoo\\tbar\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if _, ok := r.NullableString(); ok {
+		t.Fatalf("expecting NULL for `\\N`")
+	}
+	if _, ok := r.NullableInt(); ok {
+		t.Fatalf("expecting NULL for empty column")
+	}
+	if n, ok := r.NullableInt(); !ok || n != 42 {
+		t.Fatalf("unexpected value: %d, %v. Expecting 42, true", n, ok)
+	}
+	if n, ok := r.NullableInt64(); !ok || n != -1 {
+		t.Fatalf("unexpected value: %d, %v. Expecting -1, true", n, ok)
+	}
+	if n, ok := r.NullableUint64(); !ok || n != 7 {
+		t.Fatalf("unexpected value: %d, %v. Expecting 7, true", n, ok)
+	}
+	if f, ok := r.NullableFloat64(); !ok || f != 1.5 {
+		t.Fatalf("unexpected value: %v, %v. Expecting 1.5, true", f, ok)
+	}
+	if v, ok := r.NullableBool(); !ok || !v {
+		t.Fatalf("unexpected value: %v, %v. Expecting true, true", v, ok)
+	}
+	if s, ok := r.NullableString(); !ok || s != "foo\tbar" {
+		t.Fatalf("unexpected value: %q, %v. Expecting %q, true", s, ok, "foo\tbar")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Invalid values result in an error.
+	r = NewTSV(bytes.NewBufferString("foo\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if _, ok := r.NullableInt(); ok {
+		t.Fatalf("unexpected ok for invalid value")
+	}
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderNullToken(t *testing.T) {
+	r := NewCSV(bytes.NewBufferString("NULL,\\N,\"NULL\",\"\",\n"))
+	r.SetQuoting('"')
+	r.SetNullToken("NULL")
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []struct {
+		s  string
+		ok bool
+	}{{"", false}, {"N", true}, {"NULL", true}, {"", true}, {"", false}} {
+		s, ok := r.NullableString()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if s != expected.s || ok != expected.ok {
+			t.Fatalf("unexpected value: %q, %v. Expecting %q, %v", s, ok, expected.s, expected.ok)
+		}
+	}
+
+	r = NewTSV(bytes.NewBufferString("NULL\t1\n"))
+	r.SetNullToken("NULL")
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if f := r.Float64Or(-1) + r.Float64Or(-1); f != 0 {
+		t.Fatalf("unexpected sum: %v. Expecting 0", f)
+	}
+}
+
+func TestReaderHexBytes(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("48656c6c6f\t\tDEADbeef\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"Hello", "", "\xde\xad\xbe\xef"} {
+		b := r.HexBytes()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(b) != expected {
+			t.Fatalf("unexpected value: %q. Expecting %q", b, expected)
+		}
+	}
+
+	for _, s := range []string{"abc", "zz", "0x10"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if b := r.HexBytes(); b != nil {
+			t.Fatalf("unexpected value for %q: %q", s, b)
+		}
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func TestReaderBase64Bytes(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("SGVsbG8=\t\t+/8=\n-_8=\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"Hello", "", "\xfb\xff"} {
+		b := r.Base64Bytes()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(b) != expected {
+			t.Fatalf("unexpected value: %q. Expecting %q", b, expected)
+		}
+	}
+
+	r.SetBase64Encoding(base64.URLEncoding)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if b := r.Base64Bytes(); string(b) != "\xfb\xff" {
+		t.Fatalf("unexpected value: %q. Expecting %q", b, "\xfb\xff")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	r = NewTSV(bytes.NewBufferString("SGVsbG8\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if b := r.Base64Bytes(); b != nil {
+		t.Fatalf("unexpected value: %q", b)
+	}
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderSetUnescape(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("C:\\temp\\new\tfoo\\tbar\n"))
+	r.SetUnescape(false)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := r.String(); s != `C:\temp\new` {
+		t.Fatalf("unexpected value: %q. Expecting %q", s, `C:\temp\new`)
+	}
+	r.SetUnescape(true)
+	if s := r.String(); s != "foo\tbar" {
+		t.Fatalf("unexpected value: %q. Expecting %q", s, "foo\tbar")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReaderSetUnescaper(t *testing.T) {
+	// URL-like escaping with `%` instead of backslashes.
+	unescaper := func(dst, src []byte) []byte {
+		for i := 0; i < len(src); i++ {
+			if src[i] == '%' && i+2 < len(src) {
+				n, err := strconv.ParseUint(string(src[i+1:i+3]), 16, 8)
+				if err == nil {
+					dst = append(dst, byte(n))
+					i += 2
+					continue
+				}
+			}
+			dst = append(dst, src[i])
+		}
+		return dst
+	}
+
+	r := NewTSV(bytes.NewBufferString("a%09b\tc\\td\n"))
+	r.SetUnescaper(unescaper)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := r.String(); s != "a\tb" {
+		t.Fatalf("unexpected value: %q. Expecting %q", s, "a\tb")
+	}
+	if s := r.String(); s != `c\td` {
+		t.Fatalf("unexpected value: %q. Expecting %q", s, `c\td`)
+	}
+	if s := string(r.RawLine()); s != "a%09b\tc\\td\n" {
+		t.Fatalf("unexpected raw line: %q", s)
+	}
+
+	r.SetUnescaper(nil)
+	r.Reset(bytes.NewBufferString("c\\td\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := r.String(); s != "c\td" {
+		t.Fatalf("unexpected value: %q. Expecting %q", s, "c\td")
+	}
+}
+
+func TestReaderSetUnescaperMultipleCols(t *testing.T) {
+	upper := func(dst, src []byte) []byte {
+		return append(dst, bytes.ToUpper(src)...)
+	}
+
+	r := NewTSV(bytes.NewBufferString("ab\tcd\tef\n"))
+	r.SetUnescaper(upper)
+	r.Next()
+	a := r.RemainingBytes()
+	if s := fmt.Sprintf("%q", a); s != `["AB" "CD" "EF"]` {
+		t.Fatalf("unexpected values: %s. Expecting %s", s, `["AB" "CD" "EF"]`)
+	}
+
+	r = NewTSV(bytes.NewBufferString("X\tY\na\tb\n"))
+	r.SetUnescaper(upper)
+	r.Header()
+	r.Next()
+	if s := string(r.ColByName("X")); s != "A" {
+		t.Fatalf("unexpected value: %q. Expecting %q", s, "A")
+	}
+	if s := string(r.ColByName("Y")); s != "B" {
+		t.Fatalf("unexpected value: %q. Expecting %q", s, "B")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	r.SkipCol()
+	r.SkipCol()
+
+	var sb strings.Builder
+	for i := 0; i < 600; i++ {
+		if i > 0 {
+			sb.WriteByte('\t')
+		}
+		sb.WriteString(strconv.Itoa(i))
+	}
+	row := sb.String() + "\n"
+	for _, workers := range []int{0, 4} {
+		r = NewTSV(bytes.NewBufferString(row + row))
+		r.SetUnescaper(upper)
+		r.SetParallelColumns(workers)
+		r.Next()
+		ints := r.IntN(600)
+		r.Next()
+		floats := r.Float64N(600)
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error with %d workers: %s", workers, err)
+		}
+		for i := range ints {
+			if ints[i] != i || floats[i] != float64(i) {
+				t.Fatalf("unexpected values at col #%d with %d workers: %d, %v. Expecting %d", i+1, workers, ints[i], floats[i], i)
+			}
+		}
+	}
+}
+
+func TestReaderDoubledEscape(t *testing.T) {
+	testReaderDoubledEscape(t, "a||b|c\n", "a|b", "c")
+	testReaderDoubledEscape(t, "a||b||||c|d||\n", "a|b||c", "d|")
+	testReaderDoubledEscape(t, "a|b|c\n", "a", "b", "c")
+	testReaderDoubledEscape(t, "||\n", "|")
+	testReaderDoubledEscape(t, "a|\n", "a", "")
+
+	// Doubled separators are empty columns by default.
+	r := NewPSV(bytes.NewBufferString("a||b\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := r.String() + "," + r.String() + "," + r.String(); s != "a,,b" {
+		t.Fatalf("unexpected values: %q. Expecting %q", s, "a,,b")
+	}
+
+	// RawLine isn't affected.
+	r = NewPSV(bytes.NewBufferString("a||b\n"))
+	r.SetDoubledEscape(true)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	r.SkipCol()
+	if s := string(r.RawLine()); s != "a||b\n" {
+		t.Fatalf("unexpected raw line: %q", s)
+	}
+}
+
+func testReaderDoubledEscape(t *testing.T, s string, expected ...string) {
+	t.Helper()
+
+	r := NewPSV(bytes.NewBufferString(s))
+	r.SetDoubledEscape(true)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	var a []string
+	for r.HasCols() {
+		a = append(a, r.String())
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Join(a, ",") != strings.Join(expected, ",") || len(a) != len(expected) {
+		t.Fatalf("unexpected columns for %q: %q. Expecting %q", s, a, expected)
+	}
+}
+
+func TestReaderExpectedCols(t *testing.T) {
+	testReaderExpectedCols(t, 2, "a\tb\nc\td\n", 2, "")
+	testReaderExpectedCols(t, 2, "a\tb\nc\n", 1, "row #2 \"c\" contains 1 columns; expecting 2 columns")
+	testReaderExpectedCols(t, 2, "a\tb\nc\td\te\n", 1, "row #2 \"c\\td\\te\" contains 3 columns; expecting 2 columns")
+	testReaderExpectedCols(t, 2, "a\tb\n\n", 1, "row #2 \"\" contains 0 columns; expecting 2 columns")
+
+	// The first row sets the width.
+	testReaderExpectedCols(t, -1, "id\tname\n1\tfoo\n2\tbar\n", 3, "")
+	testReaderExpectedCols(t, -1, "id\tname\n1\tfoo\n2\n", 2, "row #3 \"2\" contains 1 columns; expecting 2 columns")
+
+	// Variable number of columns is allowed by default.
+	testReaderExpectedCols(t, 0, "a\nb\tc\n\nd\te\tf\n", 4, "")
+}
+
+func testReaderExpectedCols(t *testing.T, cols int, s string, expectedRows int, expectedErr string) {
+	t.Helper()
+
+	r := NewTSV(bytes.NewBufferString(s))
+	if cols < 0 {
+		r.ExpectHeaderWidth()
+	} else {
+		r.SetExpectedCols(cols)
+	}
+	rows := 0
+	for r.Next() {
+		for r.HasCols() {
+			r.SkipCol()
+		}
+		rows++
+	}
+	if rows != expectedRows {
+		t.Fatalf("unexpected number of rows: %d. Expecting %d", rows, expectedRows)
+	}
+	var errStr string
+	if err := r.Error(); err != nil {
+		errStr = err.Error()
+	}
+	if errStr != expectedErr {
+		t.Fatalf("unexpected error: %q. Expecting %q", errStr, expectedErr)
+	}
+}
+
+func TestReaderExpectedColsQuoted(t *testing.T) {
+	r := NewCSV(bytes.NewBufferString("a,\"b,c\",\"d\"\"e,\"\n1,\"x\ny\",3\n"))
+	r.SetQuoting('"')
+	r.SetExpectedCols(3)
+	for r.Next() {
+		for r.HasCols() {
+			r.SkipCol()
+		}
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	r = NewPSV(bytes.NewBufferString("a||b|c\n"))
+	r.SetDoubledEscape(true)
+	r.SetExpectedCols(2)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+}
+
+func TestReaderDecimal(t *testing.T) {
+	testReaderDecimal(t, "-123.4500", -1234500, -4)
+	testReaderDecimal(t, "42", 42, 0)
+	testReaderDecimal(t, "+0.001", 1, -3)
+	testReaderDecimal(t, ".5", 5, -1)
+	testReaderDecimal(t, "0", 0, 0)
+	testReaderDecimal(t, "922337203685477.5807", math.MaxInt64, -4)
+	testReaderDecimal(t, "-9223372036854775808", math.MinInt64, 0)
+
+	for _, s := range []string{"", "-", ".", "1.", "1.2.3", "1e5", "abc", "9223372036854775808", "92233720368547758.08", "99999999999999999999"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		r.Decimal()
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func testReaderDecimal(t *testing.T, s string, expectedMantissa int64, expectedExp int32) {
+	t.Helper()
+
+	r := NewTSV(bytes.NewBufferString(s + "\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	mantissa, exp := r.Decimal()
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if mantissa != expectedMantissa || exp != expectedExp {
+		t.Fatalf("unexpected decimal for %q: %de%d. Expecting %de%d", s, mantissa, exp, expectedMantissa, expectedExp)
+	}
+}
+
+func TestReaderIntRange(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("100\t599\t200\t-9223372036854775808\t9223372036854775807\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []int64{100, 599, 200} {
+		if n := r.IntRange(100, 599); n != expected {
+			t.Fatalf("unexpected value: %d. Expecting %d", n, expected)
+		}
+	}
+	if n := r.IntRange(math.MinInt64, 0); n != math.MinInt64 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, int64(math.MinInt64))
+	}
+	if n := r.IntRange(0, math.MaxInt64); n != math.MaxInt64 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, int64(math.MaxInt64))
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, s := range []string{"99", "600", "", "abc", "1e2", "9223372036854775808"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if n := r.IntRange(100, 599); n != 0 {
+			t.Fatalf("unexpected value for %q: %d", s, n)
+		}
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func TestReaderDecimalSeparator(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("1.234,56\t-0,5\t7\t1.234.567,8\n"))
+	r.SetDecimalSeparator(',')
+	r.SetThousandsSeparator('.')
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []float64{1234.56, -0.5, 7, 1234567.8} {
+		f := r.Float64()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if f != expected {
+			t.Fatalf("unexpected value: %v. Expecting %v", f, expected)
+		}
+	}
+	if raw := string(r.RawLine()); raw != "1.234,56\t-0,5\t7\t1.234.567,8\n" {
+		t.Fatalf("unexpected raw line: %q", raw)
+	}
+
+	// Dots aren't decimal separators without digit grouping.
+	r = NewTSV(bytes.NewBufferString("3,25\t1.5\n"))
+	r.SetDecimalSeparator(',')
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if f := r.Float32(); f != 3.25 {
+		t.Fatalf("unexpected value: %v. Expecting %v", f, 3.25)
+	}
+	_ = r.Float64()
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	// Point coordinates are localized too.
+	r = NewTSV(bytes.NewBufferString("1,5;-2.000,25\n"))
+	r.SetDecimalSeparator(',')
+	r.SetThousandsSeparator('.')
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if a, b, err := r.Point(';'); a != 1.5 || b != -2000.25 || err != nil {
+		t.Fatalf("unexpected point: %v;%v, err=%v. Expecting %v;%v", a, b, err, 1.5, -2000.25)
+	}
+
+	// The decimal separator conflicts with the column separator.
+	r = NewCSV(bytes.NewBufferString("1,5\n"))
+	r.SetDecimalSeparator(',')
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	_ = r.Float64()
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderDecimalSeparatorFloatReaders(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 600; i++ {
+		if i > 0 {
+			sb.WriteByte('\t')
+		}
+		fmt.Fprintf(&sb, "1.%03d,5", i)
+	}
+	row := sb.String() + "\n"
+	for _, workers := range []int{0, 4} {
+		r := NewTSV(bytes.NewBufferString(row))
+		r.SetDecimalSeparator(',')
+		r.SetThousandsSeparator('.')
+		r.SetParallelColumns(workers)
+		r.Next()
+		a := r.Float64N(600)
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error with %d workers: %s", workers, err)
+		}
+		for i, f := range a {
+			if expected := float64(1000+i) + 0.5; f != expected {
+				t.Fatalf("unexpected value at col #%d with %d workers: %v. Expecting %v", i+1, workers, f, expected)
+			}
+		}
+	}
+
+	r := NewTSV(bytes.NewBufferString("1,5\t\\N\t2.5\n"))
+	r.SetDecimalSeparator(',')
+	r.Next()
+	if f, ok := r.NullableFloat64(); f != 1.5 || !ok {
+		t.Fatalf("unexpected value: %v, ok=%v. Expecting %v", f, ok, 1.5)
+	}
+	if _, ok := r.NullableFloat64(); ok {
+		t.Fatalf("expecting NULL value")
+	}
+	if r.Error() != nil {
+		t.Fatalf("unexpected error: %s", r.Error())
+	}
+	if _, ok := r.NullableFloat64(); ok {
+		t.Fatalf("unexpected non-NULL value")
+	}
+	if r.Error() == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderTrimSpace(t *testing.T) {
+	r := NewCSV(bytes.NewBufferString(" 42 ,\t-7\t,   ,\" a \", b c \n"))
+	r.SetQuoting('"')
+	r.SetTrimSpace(true)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if n := r.Int(); n != 42 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, 42)
+	}
+	if n := r.Int(); n != -7 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, -7)
+	}
+	for _, expected := range []string{"", " a ", "b c"} {
+		s := r.String()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if s != expected {
+			t.Fatalf("unexpected column: %q. Expecting %q", s, expected)
+		}
+	}
+}
+
+func TestReaderReadAll(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("foo\tbar\n\na\\tb\tc\td\n"))
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := [][]string{{"foo", "bar"}, {}, {"a\tb", "c", "d"}}
+	if fmt.Sprintf("%q", rows) != fmt.Sprintf("%q", expected) {
+		t.Fatalf("unexpected rows: %q. Expecting %q", rows, expected)
+	}
+
+	r = NewCSV(bytes.NewBufferString("1,2\n3,4\n5,6"))
+	rows, err = r.ReadAll()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if !strings.Contains(err.Error(), "row #3") {
+		t.Fatalf("missing row number in error: %s", err)
+	}
+	expected = [][]string{{"1", "2"}, {"3", "4"}}
+	if fmt.Sprintf("%q", rows) != fmt.Sprintf("%q", expected) {
+		t.Fatalf("unexpected rows: %q. Expecting %q", rows, expected)
+	}
+}
+
+func TestReaderPeekCol(t *testing.T) {
+	r := NewCSV(bytes.NewBufferString("42,foo,\"a \"\"b\"\"\",c\n"))
+	r.SetQuoting('"')
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"42", "foo", `a "b"`, "c"} {
+		b1 := string(r.PeekCol())
+		b2 := string(r.PeekCol())
+		if b1 != expected || b2 != expected {
+			t.Fatalf("unexpected peeked column: %q, %q. Expecting %q", b1, b2, expected)
+		}
+		if s := r.String(); s != expected {
+			t.Fatalf("unexpected column: %q. Expecting %q", s, expected)
+		}
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if b := r.PeekCol(); b != nil {
+		t.Fatalf("unexpected peeked column after the last column: %q", b)
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if raw := string(r.RawLine()); raw != "42,foo,\"a \"\"b\"\"\",c\n" {
+		t.Fatalf("unexpected raw line: %q", raw)
+	}
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReaderRowCol(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("# comment\n1\t2\t3\t4\n5\n"))
+	r.SetComment('#')
+	if r.Row() != 0 || r.Col() != 0 {
+		t.Fatalf("unexpected position before Next: %d, %d", r.Row(), r.Col())
+	}
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	testReaderRowCol(t, r, 1, 0)
+	_ = r.Int()
+	testReaderRowCol(t, r, 1, 1)
+	r.SkipCol()
+	testReaderRowCol(t, r, 1, 2)
+	_ = r.PeekCol()
+	_ = ReadAt[int](r, 3)
+	testReaderRowCol(t, r, 1, 2)
+	_ = r.Int()
+	_ = r.Int()
+	testReaderRowCol(t, r, 1, 4)
+
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	testReaderRowCol(t, r, 2, 0)
+	_ = r.Int()
+	testReaderRowCol(t, r, 2, 1)
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if r.Row() != 2 {
+		t.Fatalf("unexpected row at the end of data: %d. Expecting %d", r.Row(), 2)
+	}
+}
+
+func testReaderRowCol(t *testing.T, r *Reader, row, col int) {
+	t.Helper()
+
+	if r.Row() != row {
+		t.Fatalf("unexpected row: %d. Expecting %d", r.Row(), row)
+	}
+	if r.Col() != col {
+		t.Fatalf("unexpected col: %d. Expecting %d", r.Col(), col)
+	}
+}
+
+func TestSniffSeparator(t *testing.T) {
+	f := func(s string, expected byte) {
+		t.Helper()
+
+		sep, r, err := SniffSeparator(&slowSource{s: []byte(s)})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if sep != expected {
+			t.Fatalf("unexpected separator for %q: %q. Expecting %q", s, sep, expected)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(data) != s {
+			t.Fatalf("unexpected data: %q. Expecting %q", data, s)
+		}
+	}
+
+	f("", ',')
+	f("foo\nbar\n", ',')
+	f("a,b,c\n1,2,3\n", ',')
+	f("a\tb\tc\n1\t2\t3\n", '\t')
+	f("a|b\n1|2\n", '|')
+	f("a;b;c\n1;2;3", ';')
+
+	// Commas inside values are inconsistent across lines.
+	f("name;amount\nfoo;1,5\nbar;2\n", ';')
+	// Equally frequent consistent separators are resolved in the order of preference.
+	f("a,b|c\n1,2|3\n", ',')
+	// The most frequent consistent separator wins.
+	f("a;b|c|d\n1;2|3|4\n", '|')
+	// The most frequent separator in the first line wins if none is consistent.
+	f("a|b|c,d\n1\n", '|')
+}
+
+func TestReaderCustomString(t *testing.T) {
+	testReaderCustomString(t, "||", &chunkSource{chunks: []string{"foo||4", "2|", "|a|b\n||1||\n"}})
+	testReaderCustomString(t, "␟", &chunkSource{chunks: []string{"foo␟42\xe2", "\x90\x9fa|b\n␟1␟\n"}})
+	testReaderCustomString(t, ",", &chunkSource{chunks: []string{"foo,42,a|b\n,1,\n"}})
+}
+
+func testReaderCustomString(t *testing.T, sep string, src io.Reader) {
+	t.Helper()
+
+	r := NewCustomString(sep, src)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := r.String(); s != "foo" {
+		t.Fatalf("unexpected column: %q. Expecting %q", s, "foo")
+	}
+	if n := r.Int(); n != 42 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, 42)
+	}
+	if s := r.String(); s != "a|b" {
+		t.Fatalf("unexpected column: %q. Expecting %q", s, "a|b")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if cols := r.RemainingCols(); fmt.Sprintf("%q", cols) != `["" "1" ""]` {
+		t.Fatalf("unexpected columns: %q. Expecting %q", cols, []string{"", "1", ""})
+	}
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReaderCustomStringUnsupported(t *testing.T) {
+	for _, f := range []func(r *Reader){
+		func(r *Reader) { r.SetQuoting('"') },
+		func(r *Reader) { r.SetDoubledEscape(true) },
+	} {
+		r := NewCustomString("||", bytes.NewBufferString("\"a||b\"||c\n"))
+		f(r)
+		if r.Next() {
+			t.Fatalf("unexpected next row")
+		}
+		err := r.Error()
+		if err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+		if errS := err.Error(); !strings.Contains(errS, "aren't supported for multi-byte separator") {
+			t.Fatalf("unexpected error: %s. Must contain %q", errS, "aren't supported for multi-byte separator")
+		}
+	}
+
+	// Enabling quoting in the middle of the row results in column error.
+	r := NewCustomString("||", bytes.NewBufferString("a||b\n"))
+	r.Next()
+	r.SetQuoting('"')
+	if s := r.String(); s != "" {
+		t.Fatalf("unexpected non-empty column: %q", s)
+	}
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderDateTimeLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("cannot load location: %s", err)
+	}
+	r := NewTSV(bytes.NewBufferString("2023-01-02 03:04:05\t2023-07-02 03:04:05\t2023-01-02 03:04:05Z\t" +
+		"2023-01-02 03:04:05.5+02:00\t2023-01-02 03:04:05-0330\t0000-00-00 00:00:00\n"))
+	r.SetLocation(loc)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []time.Time{
+		time.Date(2023, 1, 2, 8, 4, 5, 0, time.UTC),
+		time.Date(2023, 7, 2, 7, 4, 5, 0, time.UTC),
+		time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		time.Date(2023, 1, 2, 1, 4, 5, 5e8, time.UTC),
+		time.Date(2023, 1, 2, 6, 34, 5, 0, time.UTC),
+		{},
+	} {
+		dt := r.DateTime()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !dt.Equal(expected) {
+			t.Fatalf("unexpected datetime: %s. Expecting %s", dt, expected)
+		}
+	}
+}
+
+func TestReaderDuration(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("1h30m\t250ms\t-1.5s\t0\t2h3m4s5ms\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []time.Duration{90 * time.Minute, 250 * time.Millisecond, -1500 * time.Millisecond, 0,
+		2*time.Hour + 3*time.Minute + 4*time.Second + 5*time.Millisecond} {
+		d := r.Duration()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if d != expected {
+			t.Fatalf("unexpected duration: %s. Expecting %s", d, expected)
+		}
+	}
+
+	for _, s := range []string{"", "foo", "1", "1x"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		_ = r.Duration()
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("name\tage\nfoo\tbar\n"))
+	_ = r.Int()
+	if err := r.Error(); !errors.Is(err, ErrMissingNext) {
+		t.Fatalf("unexpected error: %v. Expecting %v", err, ErrMissingNext)
+	}
+
+	r = NewTSV(bytes.NewBufferString("name\tage\nfoo\tbar\n"))
+	r.Header()
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	_ = r.String()
+	_ = r.Int()
+	err := r.Error()
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("unexpected error type: %T. Expecting %T", err, pe)
+	}
+	if pe.Row != 2 || pe.Col != 2 || pe.Column != "age" {
+		t.Fatalf("unexpected error position: row %d, col %d, column %q. Expecting row 2, col 2, column %q", pe.Row, pe.Col, pe.Column, "age")
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Fatalf("unexpected underlying error: %v. Expecting %v", pe.Err, strconv.ErrSyntax)
+	}
+	expected := "cannot parse `int` at row #2, col #2 \"foo\\tbar\": strconv.Atoi: parsing \"bar\": invalid syntax"
+	if err.Error() != expected {
+		t.Fatalf("unexpected error: %q. Expecting %q", err, expected)
+	}
+
+	r = NewTSV(bytes.NewBufferString("foo"))
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); !errors.Is(err, ErrMissingNewline) {
+		t.Fatalf("unexpected error: %v. Expecting %v", err, ErrMissingNewline)
+	}
+}
+
+func TestReaderContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := NewTSV(&chunkSource{chunks: []string{"1\n2\n", "3\n", "4\n"}})
+	r.SetContext(ctx)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if n := r.Int(); n != 1 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, 1)
+	}
+	cancel()
+
+	// The buffered row is still read.
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if n := r.Int(); n != 2 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, 2)
+	}
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v. Expecting %v", err, context.Canceled)
+	}
+
+	// Long lines are interrupted between reads.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	r = NewTSV(&cancelSource{
+		r:      &chunkSource{chunks: []string{"foo", "bar", "baz\n"}},
+		cancel: cancel,
+	})
+	r.SetContext(ctx)
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v. Expecting %v", err, context.Canceled)
+	}
+}
+
+// cancelSource calls cancel after the first read from r.
+type cancelSource struct {
+	r      io.Reader
+	cancel func()
+}
+
+func (cs *cancelSource) Read(p []byte) (int, error) {
+	n, err := cs.r.Read(p)
+	cs.cancel()
+	return n, err
+}
+
+func TestReaderMaxRowSize(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("foo\tbar\nfoobar\tbaz\n"))
+	r.SetMaxRowSize(7)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if cols := r.RemainingCols(); len(cols) != 2 {
+		t.Fatalf("unexpected columns: %q", cols)
+	}
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); err == nil || !strings.Contains(err.Error(), "row #2 exceeds max row size 7") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The reader never returns a newline.
+	r = NewTSV(io.LimitReader(infiniteSource{}, 1<<30))
+	r.SetMaxRowSize(64 << 10)
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); err == nil || !strings.Contains(err.Error(), "row #1 exceeds max row size 65536") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := cap(r.scratch); n > 128<<10 {
+		t.Fatalf("unexpected scratch buffer size: %d", n)
+	}
+}
+
+// infiniteSource returns an infinite stream of `x` chars.
+type infiniteSource struct{}
+
+func (infiniteSource) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func TestReaderPool(t *testing.T) {
+	r := Get(',', bytes.NewBufferString("\"a,b\",1\n"))
+	r.SetQuoting('"')
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := r.String(); s != "a,b" {
+		t.Fatalf("unexpected column: %q. Expecting %q", s, "a,b")
+	}
+	_ = r.Int()
+	Put(r)
+
+	// Settings aren't inherited from the pooled readers.
+	r = Get('\t', bytes.NewBufferString("\"a\tb\"\n"))
+	defer Put(r)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"\"a", "b\""} {
+		if s := r.String(); s != expected {
+			t.Fatalf("unexpected column: %q. Expecting %q", s, expected)
+		}
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReaderScan(t *testing.T) {
+	type order struct {
+		ID      int       `dsv:"0"`
+		Amount  float64   `dsv:"amount"`
+		Title   string    `dsv:"title"`
+		Created time.Time `dsv:"created"`
+		Note    *string   `dsv:"note"`
+		Qty     *int8     `dsv:"5"`
+		Skipped string
+		Ignored string `dsv:"-"`
+		private string `dsv:"0"`
+	}
+
+	r := NewTSV(bytes.NewBufferString("id\ttitle\tamount\tcreated\tnote\tqty\n" +
+		"1\tfoo\t1.5\t2023-01-02 03:04:05\tbar\t3\n" +
+		"2\tbaz\\tqux\t-2\t2023-01-02 03:04:05.5\t\\N\t\n"))
+	r.Header()
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	var o order
+	if err := r.Scan(&o); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if o.ID != 1 || o.Amount != 1.5 || o.Title != "foo" || o.Skipped != "" || o.Ignored != "" || o.private != "" {
+		t.Fatalf("unexpected value: %+v", o)
+	}
+	if expected := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC); !o.Created.Equal(expected) {
+		t.Fatalf("unexpected created: %s. Expecting %s", o.Created, expected)
+	}
+	if o.Note == nil || *o.Note != "bar" {
+		t.Fatalf("unexpected note: %v. Expecting %q", o.Note, "bar")
+	}
+	if o.Qty == nil || *o.Qty != 3 {
+		t.Fatalf("unexpected qty: %v. Expecting %d", o.Qty, 3)
+	}
+
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if err := r.Scan(&o); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if o.ID != 2 || o.Amount != -2 || o.Title != "baz\tqux" || o.Note != nil || o.Qty != nil {
+		t.Fatalf("unexpected value: %+v", o)
+	}
+	if o.Created.Nanosecond() != 5e8 {
+		t.Fatalf("unexpected created: %s", o.Created)
+	}
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReaderScanError(t *testing.T) {
+	testReaderScanError(t, &struct {
+		A int `dsv:"2"`
+	}{}, "column index 2 for field A is out of range")
+	testReaderScanError(t, &struct {
+		A int `dsv:"foo"`
+	}{}, "unknown column \"foo\" for field A")
+	testReaderScanError(t, &struct {
+		A int `dsv:"b"`
+	}{}, "cannot parse `int`")
+	testReaderScanError(t, &struct {
+		A []int `dsv:"0"`
+	}{}, "unsupported field type []int")
+	testReaderScanError(t, struct{}{}, "dst must be a non-nil pointer to struct")
+}
+
+func testReaderScanError(t *testing.T, dst interface{}, errSubstr string) {
+	t.Helper()
+
+	r := NewTSV(bytes.NewBufferString("1\tx\n"))
+	r.SetColumnNames("a", "b")
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	err := r.Scan(dst)
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if !strings.Contains(err.Error(), errSubstr) {
+		t.Fatalf("unexpected error: %s. Must contain %q", err, errSubstr)
+	}
+}
+
+func TestReaderForEach(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("foo\t1\nbar\t2\n"))
+	var sb strings.Builder
+	err := r.ForEach(func(r *Reader) error {
+		fmt.Fprintf(&sb, "%s:%d,", r.String(), r.Int())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := sb.String(); s != "foo:1,bar:2," {
+		t.Fatalf("unexpected result: %q. Expecting %q", s, "foo:1,bar:2,")
+	}
+
+	// An error returned by fn stops iteration.
+	errStop := fmt.Errorf("stop")
+	r = NewTSV(bytes.NewBufferString("1\n2\n3\n"))
+	rows := 0
+	err = r.ForEach(func(r *Reader) error {
+		rows++
+		if r.Int() == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop {
+		t.Fatalf("unexpected error: %v. Expecting %v", err, errStop)
+	}
+	if rows != 2 {
+		t.Fatalf("unexpected number of rows: %d. Expecting %d", rows, 2)
+	}
+
+	// Column errors stop iteration too.
+	r = NewTSV(bytes.NewBufferString("1\nfoo\n3\n"))
+	rows = 0
+	err = r.ForEach(func(r *Reader) error {
+		rows++
+		_ = r.Int()
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if rows != 2 {
+		t.Fatalf("unexpected number of rows: %d. Expecting %d", rows, 2)
+	}
+}
+
+func TestReaderIP(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("192.168.0.1\t2001:0db8:0000:0000:0000:ff00:0042:8329\tfe80::1%eth0\t::ffff:10.0.0.1\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"192.168.0.1", "2001:db8::ff00:42:8329", "fe80::1%eth0", "::ffff:10.0.0.1"} {
+		addr := r.IP()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if addr.String() != expected {
+			t.Fatalf("unexpected ip: %q. Expecting %q", addr, expected)
+		}
+	}
+
+	for _, s := range []string{"", "foo", "256.0.0.1", "192.168.0.0/16"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if addr := r.IP(); addr.IsValid() {
+			t.Fatalf("unexpected ip for %q: %q", s, addr)
+		}
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func TestReaderPrefix(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("192.168.0.0/16\t2001:db8::/32\t10.0.0.1/32\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"192.168.0.0/16", "2001:db8::/32", "10.0.0.1/32"} {
+		prefix := r.Prefix()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if prefix.String() != expected {
+			t.Fatalf("unexpected prefix: %q. Expecting %q", prefix, expected)
+		}
+	}
+
+	for _, s := range []string{"", "192.168.0.1", "192.168.0.0/33", "fe80::/10%eth0"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if prefix := r.Prefix(); prefix.IsValid() {
+			t.Fatalf("unexpected prefix for %q: %q", s, prefix)
+		}
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func TestReaderJSON(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("{\"a\":{\"b\":[1,2]},\"c\":\"x\\\\ty\"}\t[1,\"2\",null]\t{\"a\":\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+
+	var obj struct {
+		A struct {
+			B []int `json:"b"`
+		} `json:"a"`
+		C string `json:"c"`
+	}
+	if err := r.JSON(&obj); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if fmt.Sprint(obj.A.B) != "[1 2]" || obj.C != "x\ty" {
+		t.Fatalf("unexpected object: %+v", obj)
+	}
+
+	var arr []interface{}
+	if err := r.JSON(&arr); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if fmt.Sprint(arr) != "[1 2 <nil>]" {
+		t.Fatalf("unexpected array: %v", arr)
+	}
+
+	var v interface{}
+	if err := r.JSON(&v); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := r.Error().Error(); !strings.Contains(errS, "cannot parse `json` at row #1, col #3") {
+		t.Fatalf("unexpected error: %s", errS)
+	}
+}
+
+func TestReaderSkip(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("Report\n\ngenerated\tby\tfoo\nid\tname\n1\tfoo\n"))
+	r.SetExpectedCols(2)
+	if !r.Skip(0) {
+		t.Fatalf("cannot skip zero rows: %v", r.Error())
+	}
+	if !r.Skip(3) {
+		t.Fatalf("cannot skip rows: %v", r.Error())
+	}
+	if header := r.Header(); strings.Join(header, ",") != "id,name" {
+		t.Fatalf("unexpected header: %q. Expecting %q", header, []string{"id", "name"})
+	}
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if r.Row() != 5 {
+		t.Fatalf("unexpected row: %d. Expecting %d", r.Row(), 5)
+	}
+	if cols := r.RemainingCols(); strings.Join(cols, ",") != "1,foo" {
+		t.Fatalf("unexpected columns: %q", cols)
+	}
+
+	// Skipping more rows than exist.
+	r = NewTSV(bytes.NewBufferString("a\nb\n"))
+	if r.Skip(3) {
+		t.Fatalf("unexpected skipping of missing rows")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The current row must be read.
+	r = NewTSV(bytes.NewBufferString("a\nb\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if r.Skip(1) {
+		t.Fatalf("unexpected skipping with unread columns")
+	}
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderNumCols(t *testing.T) {
+	r := NewCSV(bytes.NewBufferString("\nfoo\na,b,,\n\"x,y\",\"\"\"\",z\n"))
+	r.SetQuoting('"')
+	if n := r.NumCols(); n != 0 {
+		t.Fatalf("unexpected number of columns before Next: %d. Expecting %d", n, 0)
+	}
+	for _, expected := range []int{0, 1, 4, 3} {
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		if n := r.NumCols(); n != expected {
+			t.Fatalf("unexpected number of columns: %d. Expecting %d", n, expected)
+		}
+		if r.HasCols() {
+			_ = r.String()
+		}
+		if n := r.NumCols(); n != expected {
+			t.Fatalf("unexpected number of columns after reading a column: %d. Expecting %d", n, expected)
+		}
+		_ = r.RemainingCols()
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReaderRune(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("a\t→\t\\t\t😀\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []rune{'a', '→', '\t', '😀'} {
+		c := r.Rune()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if c != expected {
+			t.Fatalf("unexpected rune: %q. Expecting %q", c, expected)
+		}
+	}
+
+	for _, s := range []string{"", "ab", "→x", "\xff"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		_ = r.Rune()
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+
+	r = NewTSV(bytes.NewBufferString("ab\t→x\t\n"))
+	r.SetRuneLenient(true)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []rune{'a', '→'} {
+		c := r.Rune()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if c != expected {
+			t.Fatalf("unexpected rune: %q. Expecting %q", c, expected)
+		}
+	}
+	_ = r.Rune()
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error for empty value")
+	}
+}
+
+func TestReaderGzip(t *testing.T) {
+	var bb bytes.Buffer
+	zw := gzip.NewWriter(&bb)
+	if _, err := zw.Write([]byte("foo,42\nbar,123\n")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	r, err := NewCSVGzip(&bb)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var sb strings.Builder
+	for r.Next() {
+		fmt.Fprintf(&sb, "%s:%d,", r.String(), r.Int())
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := sb.String(); s != "foo:42,bar:123," {
+		t.Fatalf("unexpected result: %q. Expecting %q", s, "foo:42,bar:123,")
+	}
+
+	if _, err := NewTSVGzip(bytes.NewBufferString("foo\t42\n")); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if _, err := NewPSVGzip(bytes.NewBufferString("")); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	data := "foo\t42\t-1.5\n" +
+		"a\\tb\\nc\t\\\\\t\\0\\b\\f\\r\n" +
+		"\t\t\n" +
+		"single\n" +
+		"\n"
+	r := NewTSV(bytes.NewBufferString(data))
+	var bb bytes.Buffer
+	w := NewTSVWriter(&bb)
+	for r.Next() {
+		for r.HasCols() {
+			w.WriteBytes(r.Bytes())
+		}
+		w.EndRow()
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if bb.String() != data {
+		t.Fatalf("unexpected data: %q. Expecting %q", bb.String(), data)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var bb bytes.Buffer
+	w := NewCSVWriter(&bb)
+	w.WriteString("foo\tbar")
+	w.WriteInt(-42)
+	w.WriteInt64(1 << 40)
+	w.WriteUint64(math.MaxUint64)
+	w.WriteFloat64(0.1)
+	w.EndRow()
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "foo\\tbar,-42,1099511627776,18446744073709551615,0.1\n"
+	if bb.String() != expected {
+		t.Fatalf("unexpected data: %q. Expecting %q", bb.String(), expected)
+	}
+
+	r := NewCSV(&bb)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if s := r.String(); s != "foo\tbar" {
+		t.Fatalf("unexpected column: %q. Expecting %q", s, "foo\tbar")
+	}
+	if n := r.Int(); n != -42 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, -42)
+	}
+	if n := r.Int64(); n != 1<<40 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, int64(1<<40))
+	}
+	if n := r.Uint64(); n != math.MaxUint64 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, uint64(math.MaxUint64))
+	}
+	if f := r.Float64(); f != 0.1 {
+		t.Fatalf("unexpected value: %v. Expecting %v", f, 0.1)
+	}
+
+	// The separator cannot be escaped.
+	bb.Reset()
+	w = NewCSVWriter(&bb)
+	w.WriteString("a,b")
+	w.WriteString("c")
+	w.EndRow()
+	if err := w.Flush(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if bb.Len() != 0 {
+		t.Fatalf("unexpected data: %q", bb.String())
+	}
+}
+
+func TestReaderSetSeparator(t *testing.T) {
+	r := NewCSV(bytes.NewBufferString("a,b\n"))
+	testReaderSetSeparator(t, r, `[["a" "b"]]`)
+
+	r.Reset(bytes.NewBufferString("a,b\tc\n"))
+	r.SetSeparator('\t')
+	testReaderSetSeparator(t, r, `[["a,b" "c"]]`)
+
+	r = NewCustomString("||", bytes.NewBufferString("a||b\n"))
+	r.SetSeparator('|')
+	testReaderSetSeparator(t, r, `[["a" "" "b"]]`)
+}
+
+func testReaderSetSeparator(t *testing.T, r *Reader, expected string) {
+	t.Helper()
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := fmt.Sprintf("%q", rows); s != expected {
+		t.Fatalf("unexpected rows: %s. Expecting %s", s, expected)
+	}
+}
+
+func TestReaderNoMoreColumns(t *testing.T) {
+	for name, read := range map[string]func(r *Reader){
+		"bytes":    func(r *Reader) { r.Bytes() },
+		"int":      func(r *Reader) { r.Int() },
+		"float64":  func(r *Reader) { r.Float64() },
+		"datetime": func(r *Reader) { r.DateTime() },
+		"skip":     func(r *Reader) { r.SkipCol() },
+	} {
+		r := NewTSV(bytes.NewBufferString("1\t2\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		_ = r.Int()
+		_ = r.Int()
+		read(r)
+		err := r.Error()
+		if !errors.Is(err, ErrNoMoreColumns) {
+			t.Fatalf("unexpected error for %s: %v. Expecting %v", name, err, ErrNoMoreColumns)
+		}
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Col != 3 {
+			t.Fatalf("unexpected error position for %s: %v", name, err)
+		}
+	}
+
+	// Malformed values aren't reported as missing columns.
+	r := NewTSV(bytes.NewBufferString("1\tfoo\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	_ = r.Int()
+	_ = r.Int()
+	if err := r.Error(); err == nil || errors.Is(err, ErrNoMoreColumns) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestReaderTreatNullAsZero(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("NA\tNA\tNA\tNA\tNA\t\t\"NA\"\n"))
+	r.SetNullToken("NA")
+	r.SetQuoting('"')
+	r.SetTreatNullAsZero(true)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if n := r.Int(); n != 0 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, 0)
+	}
+	if n := r.Int8(); n != 0 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, 0)
+	}
+	if f := r.Float64(); f != 0 {
+		t.Fatalf("unexpected value: %v. Expecting %v", f, 0)
+	}
+	if d := r.Date(); !d.IsZero() {
+		t.Fatalf("unexpected date: %s. Expecting zero time", d)
+	}
+	if dt := r.DateTime(); !dt.IsZero() {
+		t.Fatalf("unexpected datetime: %s. Expecting zero time", dt)
+	}
+	if n := r.Uint64(); n != 0 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, 0)
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Quoted tokens aren't NULL.
+	_ = r.Int()
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	for _, read := range []func(r *Reader){
+		func(r *Reader) { r.Int() },
+		func(r *Reader) { r.Date() },
+	} {
+		r := NewTSV(bytes.NewBufferString("NA\n"))
+		r.SetNullToken("NA")
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		read(r)
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+}
+
+func TestReaderTreatNullAsZeroAllReaders(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("NA\tNA\tNA\tNA\tNA\tNA\n"))
+	r.SetNullToken("NA")
+	r.SetTreatNullAsZero(true)
+	r.Next()
+	if c := r.Complex64(); c != 0 {
+		t.Fatalf("unexpected value: %v. Expecting %v", c, 0)
+	}
+	if c := r.Complex128(); c != 0 {
+		t.Fatalf("unexpected value: %v. Expecting %v", c, 0)
+	}
+	if b := r.BoolInt(); b {
+		t.Fatalf("unexpected true value")
+	}
+	if f := r.Percent(); f != 0 {
+		t.Fatalf("unexpected value: %v. Expecting %v", f, 0)
+	}
+	if d := r.ISODuration(); d != 0 {
+		t.Fatalf("unexpected value: %s. Expecting %s", d, time.Duration(0))
+	}
+	if b := r.Bit(0); b {
+		t.Fatalf("unexpected true bit")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	r = NewTSV(bytes.NewBufferString("NA\tNA\tNA\tNA\tNA\tNA\n"))
+	r.SetNullToken("NA")
+	r.SetTreatNullAsZero(true)
+	r.Next()
+	if n := r.BigInt(); n == nil || n.Sign() != 0 {
+		t.Fatalf("unexpected value: %v. Expecting 0", n)
+	}
+	if f := r.BigFloat(); f == nil || f.Sign() != 0 {
+		t.Fatalf("unexpected value: %v. Expecting 0", f)
+	}
+	if m, exp := r.Decimal(); m != 0 || exp != 0 {
+		t.Fatalf("unexpected value: %de%d. Expecting 0", m, exp)
+	}
+	if n, err := r.ImpliedDecimal(2); n != 0 || err != nil {
+		t.Fatalf("unexpected value: %d, err=%v. Expecting 0", n, err)
+	}
+	if cur, units, nanos, err := r.Money('='); cur != "" || units != 0 || nanos != 0 || err != nil {
+		t.Fatalf("unexpected value: %q %d %d, err=%v. Expecting zero", cur, units, nanos, err)
+	}
+	if a, b, err := r.Point(';'); a != 0 || b != 0 || err != nil {
+		t.Fatalf("unexpected value: %v;%v, err=%v. Expecting zero", a, b, err)
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Zero must be in range for IntRange.
+	r = NewTSV(bytes.NewBufferString("NA\t\n"))
+	r.SetNullToken("NA")
+	r.SetTreatNullAsZero(true)
+	r.Next()
+	if n := r.IntRange(-5, 5); n != 0 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, 0)
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := r.IntRange(100, 599); n != 0 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, 0)
+	}
+	err := r.Error()
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if errS := err.Error(); !strings.Contains(errS, "out of range [100..599]") {
+		t.Fatalf("unexpected error: %s. Must contain %q", errS, "out of range [100..599]")
+	}
+
+	var sb strings.Builder
+	for i := 0; i < 600; i++ {
+		if i > 0 {
+			sb.WriteByte('\t')
+		}
+		if i%3 == 0 {
+			sb.WriteString("NA")
+		} else {
+			sb.WriteString(strconv.Itoa(i))
+		}
+	}
+	row := sb.String() + "\n"
+	for _, workers := range []int{0, 4} {
+		r = NewTSV(bytes.NewBufferString(row + row))
+		r.SetNullToken("NA")
+		r.SetTreatNullAsZero(true)
+		r.SetParallelColumns(workers)
+		r.Next()
+		ints := r.IntN(600)
+		r.Next()
+		floats := r.Float64N(600)
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error with %d workers: %s", workers, err)
+		}
+		for i := range ints {
+			expected := i
+			if i%3 == 0 {
+				expected = 0
+			}
+			if ints[i] != expected || floats[i] != float64(expected) {
+				t.Fatalf("unexpected values at col #%d with %d workers: %d, %v. Expecting %d", i+1, workers, ints[i], floats[i], expected)
+			}
+		}
+	}
+}
+
+func TestReaderIntLoose(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("42\t1e6\t42.0\t-3.5e2\t9223372036854775807\t-0\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []int64{42, 1e6, 42, -350, math.MaxInt64, 0} {
+		n := r.IntLoose()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if n != expected {
+			t.Fatalf("unexpected value: %d. Expecting %d", n, expected)
+		}
+	}
+
+	for _, s := range []string{"", "foo", "42.5", "1e-3", "1e19", "NaN", "Inf"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		_ = r.IntLoose()
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func TestReaderRawRow(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("a\\tb\tc\\nd\r\n"))
+	r.SetTrimCR(true)
+	if raw := r.RawRow(); raw != nil {
+		t.Fatalf("unexpected row before Next: %q", raw)
+	}
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	expected := "a\\tb\tc\\nd"
+	raw := r.RawRow()
+	if string(raw) != expected {
+		t.Fatalf("unexpected row: %q. Expecting %q", raw, expected)
+	}
+	for _, col := range []string{"a\tb", "c\nd"} {
+		if s := r.String(); s != col {
+			t.Fatalf("unexpected column: %q. Expecting %q", s, col)
+		}
+		if string(raw) != expected {
+			t.Fatalf("unexpected row after reading a column: %q. Expecting %q", raw, expected)
+		}
+		if s := string(r.RawRow()); s != expected {
+			t.Fatalf("unexpected row after reading a column: %q. Expecting %q", s, expected)
+		}
+	}
+}
+
+func TestReaderComplex(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("1.5+2.25i\t(-1-2i)\t3\t-4i\t1e3+0i\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []complex128{1.5 + 2.25i, -1 - 2i, 3, -4i} {
+		c := r.Complex128()
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if c != expected {
+			t.Fatalf("unexpected value: %v. Expecting %v", c, expected)
+		}
+	}
+	if c := r.Complex64(); c != 1000 {
+		t.Fatalf("unexpected value: %v. Expecting %v", c, 1000)
+	}
+
+	for _, s := range []string{"", "foo", "1+i2", "1+2j", "1++2i"} {
+		r := NewTSV(bytes.NewBufferString(s + "\n"))
+		if !r.Next() {
+			t.Fatalf("cannot find the next row: %v", r.Error())
+		}
+		_ = r.Complex128()
+		if err := r.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+}
+
+func TestReaderTrimCutset(t *testing.T) {
+	r := NewCSV(bytes.NewBufferString("\"42\",**foo*,\" -7\" , \"bar\"\n"))
+	r.SetTrimCutset(`"*`)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if n := r.Int(); n != 42 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, 42)
+	}
+	if s := r.String(); s != "foo" {
+		t.Fatalf("unexpected column: %q. Expecting %q", s, "foo")
+	}
+	// Spaces aren't trimmed without SetTrimSpace.
+	if s := r.String(); s != ` -7" ` {
+		t.Fatalf("unexpected column: %q. Expecting %q", s, ` -7" `)
+	}
+	if s := r.String(); s != ` "bar` {
+		t.Fatalf("unexpected column: %q. Expecting %q", s, ` "bar`)
+	}
+
+	r = NewCSV(bytes.NewBufferString("\"42\",**foo*,\" -7\" , \"bar\"\n"))
+	r.SetTrimCutset(`"*`)
+	r.SetTrimSpace(true)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	if n := r.Int(); n != 42 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, 42)
+	}
+	if s := r.String(); s != "foo" {
+		t.Fatalf("unexpected column: %q. Expecting %q", s, "foo")
+	}
+	if n := r.Int(); n != -7 {
+		t.Fatalf("unexpected value: %d. Expecting %d", n, -7)
+	}
+	if s := r.String(); s != "bar" {
+		t.Fatalf("unexpected column: %q. Expecting %q", s, "bar")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReaderSkipToNextRow(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("foo\t1\t2\nbar\tx\t3\nbaz\t4\t5\n"))
+	var sb strings.Builder
+	bad := 0
+	for r.Next() {
+		s := r.String()
+		n := r.Int()
+		m := r.Int()
+		if err := r.Error(); err != nil {
+			if r.Row() != 2 {
+				t.Fatalf("unexpected error at row #%d: %s", r.Row(), err)
+			}
+			bad++
+			r.SkipToNextRow()
+			continue
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d,", s, n, m)
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if bad != 1 {
+		t.Fatalf("unexpected number of bad rows: %d. Expecting %d", bad, 1)
+	}
+	if s := sb.String(); s != "foo:1:2,baz:4:5," {
+		t.Fatalf("unexpected result: %q. Expecting %q", s, "foo:1:2,baz:4:5,")
+	}
+
+	// The end of data isn't reset.
+	r.SkipToNextRow()
+	if r.Next() {
+		t.Fatalf("unexpected next row")
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestReaderEnum(t *testing.T) {
+	r := NewTSV(bytes.NewBufferString("buy\tsell\tBuy\thold\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"buy", "sell"} {
+		s := r.Enum("buy", "sell")
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if s != expected {
+			t.Fatalf("unexpected value: %q. Expecting %q", s, expected)
+		}
+	}
+	_ = r.Enum("buy", "sell")
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	r = NewTSV(bytes.NewBufferString("Buy\tSELL\thold\n"))
+	r.SetEnumCaseInsensitive(true)
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"buy", "sell"} {
+		s := r.Enum("buy", "sell")
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if s != expected {
+			t.Fatalf("unexpected value: %q. Expecting %q", s, expected)
+		}
+	}
+	_ = r.Enum("buy", "sell")
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestReaderEnumIn(t *testing.T) {
+	set := NewEnumSet(false, "buy", "sell")
+	r := NewTSV(bytes.NewBufferString("buy\tsell\tBuy\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"buy", "sell"} {
+		s := r.EnumIn(set)
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if s != expected {
+			t.Fatalf("unexpected value: %q. Expecting %q", s, expected)
+		}
+	}
+	_ = r.EnumIn(set)
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	set = NewEnumSet(true, "Buy", "Sell", "Übertrag")
+	r = NewTSV(bytes.NewBufferString("buy\tSELL\tÜBERTRAG\thold\n"))
+	if !r.Next() {
+		t.Fatalf("cannot find the next row: %v", r.Error())
+	}
+	for _, expected := range []string{"Buy", "Sell", "Übertrag"} {
+		s := r.EnumIn(set)
+		if err := r.Error(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if s != expected {
+			t.Fatalf("unexpected value: %q. Expecting %q", s, expected)
+		}
+	}
+	_ = r.EnumIn(set)
+	if err := r.Error(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
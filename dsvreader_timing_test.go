@@ -3,8 +3,10 @@ package dsvreader
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
+	"time"
 )
 
 func BenchmarkReaderBytes(b *testing.B) {
@@ -124,6 +126,213 @@ func benchmarkReaderUintSingleIter(b *testing.B, r *Reader, rows, cols int) {
 	}
 }
 
+func BenchmarkReaderWideRows(b *testing.B) {
+	const rows = 100
+	for _, cols := range []int{10, 500} {
+		name := fmt.Sprintf("cols_%d", cols)
+		b.Run(name, func(b *testing.B) {
+			bb := createBytesTSV(rows, cols)
+			br := bytes.NewReader(bb)
+			r := NewTSV(br)
+			b.SetBytes(int64(len(bb)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				benchmarkReaderBytesSingleIter(b, r, rows, cols)
+				br.Reset(bb)
+				r.Reset(br)
+			}
+		})
+	}
+}
+
+func BenchmarkCountRows(b *testing.B) {
+	for _, rows := range []int{100, 1e3, 1e4} {
+		for _, cols := range []int{1, 10, 100} {
+			name := fmt.Sprintf("%d_%d", rows, cols)
+			b.Run(name, func(b *testing.B) {
+				benchmarkCountRows(b, rows, cols)
+			})
+		}
+	}
+}
+
+func benchmarkCountRows(b *testing.B, rows, cols int) {
+	b.StopTimer()
+	bb := createBytesTSV(rows, cols)
+	br := bytes.NewReader(bb)
+	b.StartTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		n, err := CountRows(br, '\t')
+		if err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+		if n != rows {
+			b.Fatalf("unexpected number of rows: %d. Expecting %d", n, rows)
+		}
+		br.Reset(bb)
+	}
+}
+
+func BenchmarkReaderNewBytes(b *testing.B) {
+	const rows, cols = 100, 10
+	bb := createBytesTSV(rows, cols)
+	b.Run("NewBytes", func(b *testing.B) {
+		b.SetBytes(int64(len(bb)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r := NewBytes('\t', bb)
+			benchmarkReaderBytesSingleIter(b, r, rows, cols)
+		}
+	})
+	b.Run("NewTSV", func(b *testing.B) {
+		b.SetBytes(int64(len(bb)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r := NewTSV(bytes.NewReader(bb))
+			benchmarkReaderBytesSingleIter(b, r, rows, cols)
+		}
+	})
+}
+
+func BenchmarkReaderBufferSize(b *testing.B) {
+	for _, size := range []int{0, 64 << 10} {
+		name := fmt.Sprintf("size_%d", size)
+		b.Run(name, func(b *testing.B) {
+			benchmarkReaderBufferSize(b, size)
+		})
+	}
+}
+
+func benchmarkReaderBufferSize(b *testing.B, size int) {
+	const rows, cols = 100, 1000
+	bb := createBytesTSV(rows, cols)
+	br := bytes.NewReader(bb)
+	r := NewTSV(br)
+	r.SetBufferSize(size)
+	b.SetBytes(int64(len(bb)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkReaderBytesSingleIter(b, r, rows, cols)
+		br.Reset(bb)
+		r.Reset(br)
+	}
+}
+
+func BenchmarkReaderReset(b *testing.B) {
+	bb := createBytesTSV(10, 10)
+	b.Run("ResetKeepBuffers", func(b *testing.B) {
+		br := bytes.NewReader(bb)
+		r := NewTSV(br)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			br.Reset(bb)
+			r.ResetKeepBuffers(br)
+			benchmarkReaderBytesSingleIter(b, r, 10, 10)
+		}
+	})
+	b.Run("NewTSV", func(b *testing.B) {
+		br := bytes.NewReader(bb)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			br.Reset(bb)
+			r := NewTSV(br)
+			benchmarkReaderBytesSingleIter(b, r, 10, 10)
+		}
+	})
+}
+
+func BenchmarkReaderPool(b *testing.B) {
+	bb := createBytesTSV(10, 10)
+	b.Run("GetPut", func(b *testing.B) {
+		br := bytes.NewReader(bb)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			br.Reset(bb)
+			r := Get('\t', br)
+			benchmarkReaderBytesSingleIter(b, r, 10, 10)
+			Put(r)
+		}
+	})
+	b.Run("NewTSV", func(b *testing.B) {
+		br := bytes.NewReader(bb)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			br.Reset(bb)
+			r := NewTSV(br)
+			benchmarkReaderBytesSingleIter(b, r, 10, 10)
+		}
+	})
+}
+
+func BenchmarkReaderPrefetch(b *testing.B) {
+	for _, buffers := range []int{0, 4} {
+		name := fmt.Sprintf("buffers_%d", buffers)
+		b.Run(name, func(b *testing.B) {
+			benchmarkReaderPrefetch(b, buffers)
+		})
+	}
+}
+
+func benchmarkReaderPrefetch(b *testing.B, buffers int) {
+	const rows, cols = 1e3, 10
+	bb := createIntTSV(rows, cols)
+	b.SetBytes(int64(len(bb)))
+	b.ReportAllocs()
+	r := NewTSV(nil)
+	r.SetPrefetch(buffers)
+	for i := 0; i < b.N; i++ {
+		r.Reset(&latencySource{r: bytes.NewReader(bb), latency: 20 * time.Microsecond})
+		benchmarkReaderIntSingleIter(b, r, rows, cols)
+		if r.Next() {
+			b.Fatalf("unexpected next row")
+		}
+	}
+}
+
+// latencySource simulates slow IO by delaying every read.
+type latencySource struct {
+	r       io.Reader
+	latency time.Duration
+}
+
+func (ls *latencySource) Read(p []byte) (int, error) {
+	time.Sleep(ls.latency)
+	return ls.r.Read(p)
+}
+
+func BenchmarkReaderParallelColumns(b *testing.B) {
+	for _, workers := range []int{1, 2, 4, 8} {
+		name := fmt.Sprintf("workers_%d", workers)
+		b.Run(name, func(b *testing.B) {
+			benchmarkReaderParallelColumns(b, workers)
+		})
+	}
+}
+
+func benchmarkReaderParallelColumns(b *testing.B, workers int) {
+	const rows, cols = 10, 1e4
+	bb := createIntTSV(rows, cols)
+	br := bytes.NewReader(bb)
+	r := NewTSV(br)
+	r.SetParallelColumns(workers)
+	b.SetBytes(int64(len(bb)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < rows; j++ {
+			if !r.Next() {
+				b.Fatalf("Reader.Next must return true on row #%d", j+1)
+			}
+			if a := r.IntN(cols); a == nil {
+				b.Fatalf("unexpected error: %s", r.Error())
+			}
+		}
+		br.Reset(bb)
+		r.Reset(br)
+	}
+}
+
 func createBytesTSV(rows, cols int) []byte {
 	var bb bytes.Buffer
 	for i := 0; i < rows; i++ {
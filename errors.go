@@ -0,0 +1,58 @@
+package dsvreader
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoMoreColumns is returned when reading past the last column of a row.
+	//
+	// Column errors set on the Reader wrap it, so errors.Is(tr.Error(), ErrNoMoreColumns)
+	// distinguishes missing trailing columns from malformed values.
+	// See also SetMissingColumnValue.
+	ErrNoMoreColumns = errors.New("no more columns")
+
+	// ErrMissingNext is returned when reading columns before the first Next call.
+	ErrMissingNext = errors.New("missing Next call")
+
+	// ErrMissingNewline is returned when the last row isn't terminated
+	// by a newline.
+	ErrMissingNewline = errors.New("cannot find newline")
+)
+
+// ParseError is the error returned by Reader.Error when a column
+// cannot be read or parsed.
+//
+// Use errors.As for inspecting the error position and errors.Is
+// for checking the underlying error:
+//
+//	var pe *dsvreader.ParseError
+//	if errors.As(tr.Error(), &pe) {
+//		log.Printf("bad column %q at row %d", pe.Column, pe.Row)
+//	}
+type ParseError struct {
+	// Row is the 1-based number of the row, see Reader.Row.
+	Row int
+
+	// Col is the 1-based number of the column, see Reader.Col.
+	Col int
+
+	// Column is the column name from the header or empty
+	// if the header isn't read.
+	Column string
+
+	// Err is the underlying error.
+	Err error
+
+	msg        string
+	rowContext string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at row #%d, col #%d %s: %s", e.msg, e.Row, e.Col, e.rowContext, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
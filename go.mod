@@ -1 +1,3 @@
 module github.com/cristaloleg/dsvreader
+
+go 1.18
@@ -0,0 +1,43 @@
+package dsvreader
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// NewCSVGzip returns new Reader that reads gzip-compressed CSV data from r.
+//
+// An error is returned if r doesn't start with a valid gzip header.
+func NewCSVGzip(r io.Reader) (*Reader, error) {
+	return newGzip(',', r)
+}
+
+// NewTSVGzip returns new Reader that reads gzip-compressed TSV data from r.
+//
+// An error is returned if r doesn't start with a valid gzip header.
+func NewTSVGzip(r io.Reader) (*Reader, error) {
+	return newGzip('\t', r)
+}
+
+// NewPSVGzip returns new Reader that reads gzip-compressed PSV data from r.
+//
+// An error is returned if r doesn't start with a valid gzip header.
+func NewPSVGzip(r io.Reader) (*Reader, error) {
+	return newGzip('|', r)
+}
+
+// newGzip returns new Reader that reads gzip-compressed data delimited
+// by sep from r.
+//
+// There is no need in closing the gzip reader, since it doesn't
+// close r and holds no other resources. The caller remains responsible
+// for closing r if needed. Checksum errors are reported by Next
+// at the end of data.
+func newGzip(sep byte, r io.Reader) (*Reader, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read gzip header: %w", err)
+	}
+	return NewCustom(sep, zr), nil
+}
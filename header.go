@@ -0,0 +1,326 @@
+package dsvreader
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetRequiredColumns sets column names, which must be present in the header.
+//
+// The columns may go in any order and the header may contain other columns.
+// Header results in an error listing the missing columns.
+func (tr *Reader) SetRequiredColumns(names ...string) {
+	tr.requiredCols = append(tr.requiredCols[:0], names...)
+}
+
+// Header reads the next row as a header and returns column names from it.
+//
+// The header must contain all the columns set via SetRequiredColumns.
+// nil is returned on error.
+func (tr *Reader) Header() []string {
+	header := tr.readHeaderRow()
+	if tr.err != nil {
+		return nil
+	}
+	return tr.useHeader(header)
+}
+
+// ReadHeader reads the remaining columns of the current row as a header
+// and returns column names from it.
+//
+// Unlike Header, it doesn't advance to the next row, so it may be used
+// after inspecting the row with Next and column readers.
+// The header must contain all the columns set via SetRequiredColumns.
+// nil is returned on error.
+func (tr *Reader) ReadHeader() []string {
+	if tr.err != nil {
+		return nil
+	}
+	var header []string
+	for {
+		name := tr.String()
+		if tr.err != nil {
+			return nil
+		}
+		header = append(header, name)
+		if !tr.HasCols() {
+			break
+		}
+	}
+	return tr.useHeader(header)
+}
+
+func (tr *Reader) useHeader(header []string) []string {
+	if tr.columnNames != nil && !equalNames(header, tr.columnNames) {
+		tr.err = fmt.Errorf("header at row #%d %s conflicts with column names %q", tr.row, tr.quoteRow(tr.rowBuf), tr.columnNames)
+		return nil
+	}
+	tr.setHeader(header)
+	var missing []string
+	for _, name := range tr.requiredCols {
+		if _, ok := tr.headerIdx[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		tr.err = fmt.Errorf("missing required columns %q in header at row #%d %s", missing, tr.row, tr.quoteRow(tr.rowBuf))
+		return nil
+	}
+	return header
+}
+
+// SetColumnNames sets column names for data without header row.
+//
+// This enables access to columns by name. Header results in an error
+// if it reads a header with different names.
+//
+// Column names are preserved across Reset calls.
+func (tr *Reader) SetColumnNames(names ...string) {
+	tr.columnNames = append([]string{}, names...)
+	tr.setHeader(tr.columnNames)
+}
+
+// ColIndex returns the zero-based index of the column with the given name.
+//
+// Column names are set either by reading the header or via SetColumnNames.
+func (tr *Reader) ColIndex(name string) (int, bool) {
+	idx, ok := tr.headerIdx[name]
+	return idx, ok
+}
+
+// StringByName returns the string value of the column with the given name
+// from the current row.
+//
+// Like ReadAt, it doesn't consume columns.
+func (tr *Reader) StringByName(name string) string {
+	if tr.err != nil {
+		return ""
+	}
+	idx, ok := tr.ColIndex(name)
+	if !ok {
+		tr.setColError("cannot read `string`", fmt.Errorf("unknown column %q", name))
+		return ""
+	}
+	return ReadAt[string](tr, idx)
+}
+
+// RowMap reads the remaining columns of the current row and returns them
+// keyed by column names.
+//
+// Column names are set either by reading the header or via SetColumnNames.
+// Columns missing at the end of short rows are omitted from the map,
+// while columns beyond the header result in an error. If the header
+// contains duplicate names, the first column with the name wins.
+func (tr *Reader) RowMap() (map[string]string, error) {
+	if tr.err != nil {
+		return nil, tr.err
+	}
+	if tr.header == nil {
+		tr.setColError("cannot read row map", fmt.Errorf("missing header"))
+		return nil, tr.err
+	}
+	m := make(map[string]string, len(tr.header))
+	for tr.HasCols() {
+		if tr.col >= len(tr.header) {
+			tr.col++
+			tr.setColError("cannot read row map", fmt.Errorf("column is missing in the header with %d columns", len(tr.header)))
+			return nil, tr.err
+		}
+		name := tr.header[tr.col]
+		s := tr.String()
+		if tr.err != nil {
+			return nil, tr.err
+		}
+		if _, ok := m[name]; !ok {
+			m[name] = s
+		}
+	}
+	return m, nil
+}
+
+// ColByName returns the bytes value of the column with the given name
+// from the current row.
+//
+// Columns may be requested in any order. The first call on a row splits
+// the whole row into columns, so the row is consumed and sequential
+// column readers cannot be used on it after the call.
+// Column names are set either by reading the header or via SetColumnNames.
+//
+// The returned value is valid until the Next call.
+func (tr *Reader) ColByName(name string) []byte {
+	if tr.err != nil {
+		return nil
+	}
+	idx, ok := tr.ColIndex(name)
+	if !ok {
+		tr.setColError("cannot read column by name", fmt.Errorf("unknown column %q", name))
+		return nil
+	}
+	if tr.row == 0 {
+		tr.setColError("cannot read column by name", ErrMissingNext)
+		return nil
+	}
+	if tr.namedRow != tr.row {
+		if !tr.splitNamedRow() {
+			return nil
+		}
+	}
+	if idx >= len(tr.namedCols) {
+		tr.setColError("cannot read column by name", fmt.Errorf("missing column %q at index %d in the row with %d columns", name, idx, len(tr.namedCols)))
+		return nil
+	}
+	return tr.namedCols[idx]
+}
+
+// splitNamedRow splits the current row into tr.namedCols.
+func (tr *Reader) splitNamedRow() bool {
+	// Split a copy of the row, since previously read columns
+	// may be modified in place.
+	tr.namedBuf = append(tr.namedBuf[:0], tr.unmutatedRow()...)
+	tr.b, tr.col = tr.namedBuf, 0
+	if tr.rowBuf == nil {
+		tr.b = nil
+	}
+	cols := tr.namedCols[:0]
+	for tr.HasCols() {
+		b := tr.Bytes()
+		if tr.err != nil {
+			return false
+		}
+		cols = append(cols, b)
+	}
+	tr.namedCols = cols
+	tr.namedRow = tr.row
+	return true
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// setHeader sets column names for the subsequent rows.
+//
+// The first column wins if the header contains duplicate names.
+func (tr *Reader) setHeader(header []string) {
+	tr.header = header
+	tr.headerIdx = make(map[string]int, len(header))
+	for i, name := range header {
+		if _, ok := tr.headerIdx[name]; !ok {
+			tr.headerIdx[name] = i
+		}
+	}
+}
+
+// SetHeaderAnyOrder controls whether ExpectHeader accepts the expected
+// column names in any order.
+//
+// By default the columns must be in the expected order.
+func (tr *Reader) SetHeaderAnyOrder(anyOrder bool) {
+	tr.headerAnyOrder = anyOrder
+}
+
+// ExpectHeader reads the next row as a header and verifies it contains
+// exactly the given column names.
+//
+// The returned error describes the difference between the header
+// and the expected names. The error is also available via Error.
+func (tr *Reader) ExpectHeader(names ...string) error {
+	header := tr.readHeaderRow()
+	if tr.err != nil {
+		return tr.err
+	}
+
+	var diff []string
+	if tr.headerAnyOrder {
+		diff = diffNamesAnyOrder(header, names)
+	} else {
+		diff = diffNames(header, names)
+	}
+	if len(diff) > 0 {
+		tr.err = fmt.Errorf("unexpected header at row #%d %s: %s", tr.row, tr.quoteRow(tr.rowBuf), strings.Join(diff, "; "))
+		return tr.err
+	}
+	tr.setHeader(header)
+	return nil
+}
+
+// SetHeaderRowIndex sets the zero-based index of the header row
+// for files with banner lines before the header.
+//
+// Header and ExpectHeader skip rows before the header row regardless
+// of their contents and width, see SetExpectedCols and ExpectHeaderWidth. The skipped rows are counted in row numbers
+// in error messages. The default index is 0.
+func (tr *Reader) SetHeaderRowIndex(n int) {
+	tr.headerRowIdx = n
+}
+
+// readHeaderRow reads the next row and returns its columns.
+func (tr *Reader) readHeaderRow() []string {
+	// Skip banner rows without checking their width.
+	if n := tr.headerRowIdx - tr.row; n > 0 && !tr.Skip(n) {
+		if tr.Error() == nil {
+			tr.err = fmt.Errorf("cannot find header row #%d", tr.headerRowIdx+1)
+		}
+		return nil
+	}
+	if !tr.Next() {
+		if tr.Error() == nil {
+			tr.err = fmt.Errorf("cannot find header row")
+		}
+		return nil
+	}
+	var header []string
+	for tr.HasCols() {
+		name := tr.String()
+		if tr.err != nil {
+			return nil
+		}
+		header = append(header, name)
+	}
+	return header
+}
+
+func diffNames(header, names []string) []string {
+	var diff []string
+	for i := 0; i < len(header) || i < len(names); i++ {
+		switch {
+		case i >= len(header):
+			diff = append(diff, fmt.Sprintf("missing col #%d %q", i+1, names[i]))
+		case i >= len(names):
+			diff = append(diff, fmt.Sprintf("unexpected col #%d %q", i+1, header[i]))
+		case header[i] != names[i]:
+			diff = append(diff, fmt.Sprintf("col #%d is %q instead of %q", i+1, header[i], names[i]))
+		}
+	}
+	return diff
+}
+
+func diffNamesAnyOrder(header, names []string) []string {
+	m := make(map[string]int, len(header))
+	for _, name := range header {
+		m[name]++
+	}
+	var diff []string
+	for _, name := range names {
+		if m[name] == 0 {
+			diff = append(diff, fmt.Sprintf("missing col %q", name))
+			continue
+		}
+		m[name]--
+	}
+	for _, name := range header {
+		if m[name] > 0 {
+			diff = append(diff, fmt.Sprintf("unexpected col %q", name))
+			m[name]--
+		}
+	}
+	return diff
+}
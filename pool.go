@@ -0,0 +1,53 @@
+package dsvreader
+
+import (
+	"io"
+	"sync"
+)
+
+var readerPool sync.Pool
+
+// Get returns a Reader for reading data delimited by sep from r.
+//
+// The Reader is taken from the pool of readers returned via Put
+// or created if the pool is empty. Pooled readers reuse the memory
+// allocated for internal buffers, which reduces GC pressure when
+// many short streams are read, e.g. a stream per request.
+//
+// The returned Reader has the default settings like a Reader
+// returned by NewCustom.
+func Get(sep byte, r io.Reader) *Reader {
+	v := readerPool.Get()
+	if v == nil {
+		return NewCustom(sep, r)
+	}
+	tr := v.(*Reader)
+	tr.sep = sep
+	tr.Reset(r)
+	return tr
+}
+
+// Put returns tr to the pool of readers used by Get.
+//
+// The settings of tr are reset and references to the underlying reader
+// and the read data are dropped. tr and values returned by it mustn't be
+// used after the call.
+func Put(tr *Reader) {
+	tr.stopPrefetch()
+	*tr = Reader{
+		scratch:      tr.scratch[:0],
+		origRow:      tr.origRow[:0],
+		badRow:       tr.badRow[:0],
+		expandBuf:    tr.expandBuf[:0],
+		namedBuf:     tr.namedBuf[:0],
+		keepBuf:      tr.keepBuf[:0],
+		keepRawBuf:   tr.keepRawBuf[:0],
+		lineBuf:      tr.lineBuf[:0],
+		unescapeBuf:  tr.unescapeBuf[:0],
+		atBuf:        tr.atBuf[:0],
+		peekColBuf:   tr.peekColBuf[:0],
+		colBuf:       tr.colBuf[:0],
+		prefetchBufs: tr.prefetchBufs,
+	}
+	readerPool.Put(tr)
+}
@@ -0,0 +1,147 @@
+package dsvreader
+
+import (
+	"io"
+)
+
+// SetPrefetch enables reading ahead of the parsed data into the given
+// number of buffers on a background goroutine.
+//
+// This overlaps reading from slow sources such as disks or network
+// with parsing. The Reader itself must still be used from a single goroutine.
+// At least two buffers are needed for the overlap.
+// Pass 0 for disabling prefetching. This is the default.
+//
+// The background goroutine is started on the first read and stops
+// when the end of data or a read error is reached. Reset stops it
+// after waiting for the in-flight read, so the reader passed to the previous
+// Reset may be reused after that. Call Reset if the reader is abandoned
+// before reaching the end of data, otherwise the goroutine leaks.
+//
+// Changing the number of buffers takes effect after the next Reset
+// if the background goroutine is already running.
+func (tr *Reader) SetPrefetch(buffers int) {
+	if buffers < 0 {
+		buffers = 0
+	}
+	tr.prefetch = buffers
+}
+
+// prefetcher reads chunks from r on a background goroutine.
+type prefetcher struct {
+	// full contains chunks read from r in the reading order.
+	// Its capacity equals to the number of buffers, so sending to it never blocks.
+	full chan prefetchChunk
+
+	// free contains buffers ready for reading into.
+	free chan []byte
+
+	stop chan struct{}
+	done chan struct{}
+
+	// cur is the buffer the Reader currently parses.
+	cur []byte
+}
+
+type prefetchChunk struct {
+	b   []byte
+	err error
+}
+
+func (tr *Reader) startPrefetch() {
+	n := tr.prefetch
+	pf := &prefetcher{
+		full: make(chan prefetchChunk, n),
+		free: make(chan []byte, n),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	size := len(tr.readBuf())
+	for i := 0; i < n; i++ {
+		var buf []byte
+		if len(tr.prefetchBufs) > 0 && len(tr.prefetchBufs[len(tr.prefetchBufs)-1]) == size {
+			buf = tr.prefetchBufs[len(tr.prefetchBufs)-1]
+			tr.prefetchBufs = tr.prefetchBufs[:len(tr.prefetchBufs)-1]
+		} else {
+			buf = make([]byte, size)
+		}
+		pf.free <- buf
+	}
+	tr.pf = pf
+	go pf.run(tr.r)
+}
+
+func (pf *prefetcher) run(r io.Reader) {
+	defer close(pf.done)
+	for {
+		var buf []byte
+		select {
+		case buf = <-pf.free:
+		case <-pf.stop:
+			return
+		}
+		n, err := r.Read(buf)
+		pf.full <- prefetchChunk{b: buf[:n], err: err}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// next returns the next chunk read from the underlying reader.
+//
+// The previously returned chunk is recycled, so it mustn't be used after the call.
+func (pf *prefetcher) next() ([]byte, error) {
+	if pf.cur != nil {
+		pf.free <- pf.cur[:cap(pf.cur)]
+		pf.cur = nil
+	}
+	c := <-pf.full
+	pf.cur = c.b
+	return c.b, c.err
+}
+
+// stopPrefetch stops the background goroutine and returns the data
+// it has read ahead together with the read error if any.
+func (tr *Reader) stopPrefetch() (pending []byte, err error) {
+	pf := tr.pf
+	if pf == nil {
+		return nil, nil
+	}
+	tr.pf = nil
+	close(pf.stop)
+	<-pf.done
+
+	bufs := tr.prefetchBufs
+	if pf.cur != nil {
+		bufs = append(bufs, pf.cur[:cap(pf.cur)])
+	}
+	for len(pf.full) > 0 {
+		c := <-pf.full
+		pending = append(pending, c.b...)
+		if c.err != nil {
+			err = c.err
+		}
+		bufs = append(bufs, c.b[:cap(c.b)])
+	}
+	for len(pf.free) > 0 {
+		bufs = append(bufs, <-pf.free)
+	}
+	tr.prefetchBufs = bufs
+	return pending, err
+}
+
+// readChunk reads the next chunk of data from the underlying reader.
+//
+// The returned chunk is valid until the next readChunk call.
+func (tr *Reader) readChunk() ([]byte, error) {
+	if tr.prefetch == 0 && tr.pf == nil {
+		buf := tr.readBuf()
+		n, err := tr.r.Read(buf)
+		return buf[:n], err
+	}
+	if tr.pf == nil {
+		tr.startPrefetch()
+	}
+	return tr.pf.next()
+}
@@ -0,0 +1,178 @@
+package dsvreader
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// SetQuoting enables RFC 4180 quoted fields with the given quote char,
+// usually `"`.
+//
+// A field starting with the quote char lasts until the matching closing
+// quote, so it may contain separators and newlines. Doubled quote chars
+// inside a quoted field stand for a single quote char, e.g. `"a ""b"", c"`
+// is read as `a "b", c`. Quoted fields aren't backslash-unescaped,
+// while unquoted fields are handled as usual. Quote chars inside unquoted
+// fields are read literally.
+//
+// Pass 0 for disabling quoting. This is the default.
+func (tr *Reader) SetQuoting(quote byte) {
+	tr.quote = quote
+	tr.quoteState = quoteFieldStart
+	tr.updateSlowCols()
+}
+
+// FieldQuoted returns the next bytes column value from the current row
+// and whether the column was quoted.
+//
+// The returned value is unquoted and unescaped like the one returned
+// by Bytes. This allows re-quoting the column on output. The column
+// is never quoted if quoting is disabled, see SetQuoting.
+//
+// The returned value is valid until the next call to Reader.
+func (tr *Reader) FieldQuoted() ([]byte, bool) {
+	b := tr.Bytes()
+	if tr.err != nil {
+		return nil, false
+	}
+	return b, tr.colQuoted
+}
+
+// SetLazyQuotes controls whether malformed quoted fields are read
+// leniently instead of resulting in errors.
+//
+// A quote char inside a quoted field, which isn't doubled and isn't
+// followed by the separator or the end of row, is read literally,
+// e.g. `"a"b",c` is read as `a"b` and `c`. A quoted field without
+// the closing quote lasts until the end of data.
+//
+// This is similar to LazyQuotes in encoding/csv. Disabled by default.
+func (tr *Reader) SetLazyQuotes(lazy bool) {
+	tr.lazyQuotes = lazy
+}
+
+// quoteState is the state of quote-aware row splitting.
+type quoteState int
+
+const (
+	quoteFieldStart quoteState = iota
+	quoteUnquoted
+	quoteQuoted
+	// quoteQuotedQuote means a quote char was found inside a quoted field.
+	// It is either the closing quote or the first one of doubled quotes.
+	quoteQuotedQuote
+)
+
+// findRowEnd returns the index of the newline terminating the current row
+// in b or -1 if b doesn't contain it.
+//
+// Newlines inside quoted fields are skipped. The quoting state is kept
+// in tr between calls, so rows may span multiple buffers.
+func (tr *Reader) findRowEnd(b []byte) int {
+	if tr.quote == 0 {
+		return bytes.IndexByte(b, '\n')
+	}
+
+	state := tr.quoteState
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch state {
+		case quoteQuoted:
+			// Fast path - skip to the next quote char.
+			n := bytes.IndexByte(b[i:], tr.quote)
+			if n < 0 {
+				tr.quoteState = state
+				return -1
+			}
+			i += n
+			state = quoteQuotedQuote
+			continue
+		case quoteFieldStart:
+			if c == tr.quote {
+				state = quoteQuoted
+				continue
+			}
+		case quoteQuotedQuote:
+			if c == tr.quote || tr.lazyQuotes && c != tr.sep && c != '\n' && c != '\r' {
+				// Either doubled quotes or a literal quote in lazy mode.
+				state = quoteQuoted
+				continue
+			}
+		}
+		switch c {
+		case '\n':
+			tr.quoteState = quoteFieldStart
+			return i
+		case tr.sep:
+			state = quoteFieldStart
+		default:
+			state = quoteUnquoted
+		}
+	}
+	tr.quoteState = state
+	return -1
+}
+
+// nextQuotedCol returns the quoted column at the start of tr.b.
+//
+// Doubled quote chars are collapsed in place.
+func (tr *Reader) nextQuotedCol() ([]byte, error) {
+	b := tr.b[1:]
+	doubled := false
+	end := -1
+	for i := 0; i < len(b); i++ {
+		n := bytes.IndexByte(b[i:], tr.quote)
+		if n < 0 {
+			break
+		}
+		i += n
+		if i+1 < len(b) && b[i+1] == tr.quote {
+			doubled = true
+			i++
+			continue
+		}
+		if tr.lazyQuotes && i+1 < len(b) && b[i+1] != tr.sep {
+			// Literal quote char.
+			continue
+		}
+		end = i
+		break
+	}
+	if end < 0 {
+		// Consume the rest of the row, so it could be skipped after the error.
+		tr.b = nil
+		if !tr.lazyQuotes {
+			return nil, fmt.Errorf("missing closing quote")
+		}
+		// The field lasts until the end of row.
+		end = len(b)
+	} else {
+		rest := b[end+1:]
+		switch {
+		case len(rest) == 0:
+			tr.b = nil
+		case rest[0] == tr.sep:
+			tr.b = rest[1:]
+		default:
+			tr.b = nil
+			return nil, fmt.Errorf("unexpected %q after closing quote", rest[0])
+		}
+	}
+	tr.colQuoted = true
+
+	b = b[:end]
+	if !doubled {
+		return b, nil
+	}
+
+	// Slow path - collapse doubled quotes in place.
+	tr.saveRow()
+	dst := b[:0]
+	for i := 0; i < len(b); i++ {
+		dst = append(dst, b[i])
+		if b[i] == tr.quote && i+1 < len(b) && b[i+1] == tr.quote {
+			i++
+		}
+	}
+	return dst, nil
+}
@@ -0,0 +1,88 @@
+package dsvreader
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReadAt returns the column with the given zero-based index from the current
+// row parsed as T.
+//
+// ReadAt doesn't consume columns, so it may be used for sparse access
+// to wide rows and may be mixed with column readers. Columns are always
+// counted from the start of the row.
+//
+// The following types are supported: string, []byte, int, int8, int16,
+// int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool
+// and time.Time, which is parsed with DateTime.
+func ReadAt[T any](tr *Reader, idx int) T {
+	var v T
+	tr.readAt(idx, func() {
+		tr.readValue(&v)
+	})
+	return v
+}
+
+// readAt calls read for reading the column with the given zero-based index
+// from the current row without consuming columns.
+func (tr *Reader) readAt(idx int, read func()) {
+	if tr.err != nil {
+		return
+	}
+
+	// Read the column from a copy of the row, since column readers
+	// may modify it in place.
+	b, col := tr.b, tr.col
+	tr.atBuf = append(tr.atBuf[:0], tr.unmutatedRow()...)
+	tr.b, tr.col = tr.atBuf, 0
+	if tr.rowBuf == nil {
+		tr.b = nil
+	}
+	for i := 0; i < idx && tr.err == nil; i++ {
+		tr.SkipCol()
+	}
+	if tr.err == nil {
+		read()
+	}
+	tr.b, tr.col = b, col
+}
+
+func (tr *Reader) readValue(v interface{}) {
+	switch p := v.(type) {
+	case *string:
+		*p = tr.String()
+	case *[]byte:
+		*p = tr.Bytes()
+	case *int:
+		*p = tr.Int()
+	case *int8:
+		*p = tr.Int8()
+	case *int16:
+		*p = tr.Int16()
+	case *int32:
+		*p = tr.Int32()
+	case *int64:
+		*p = tr.Int64()
+	case *uint:
+		*p = tr.Uint()
+	case *uint8:
+		*p = tr.Uint8()
+	case *uint16:
+		*p = tr.Uint16()
+	case *uint32:
+		*p = tr.Uint32()
+	case *uint64:
+		*p = tr.Uint64()
+	case *float32:
+		*p = tr.Float32()
+	case *float64:
+		*p = tr.Float64()
+	case *bool:
+		*p = tr.Bool()
+	case *time.Time:
+		*p = tr.DateTime()
+	default:
+		tr.col++
+		tr.setColError("cannot read column", fmt.Errorf("unsupported type %T", v))
+	}
+}
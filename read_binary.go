@@ -0,0 +1,73 @@
+package dsvreader
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// HexBytes returns the next hex-encoded column value from the current row
+// decoded to bytes.
+//
+// The returned value is valid until the next call to Reader.
+func (tr *Reader) HexBytes() []byte {
+	if tr.err != nil {
+		return nil
+	}
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read `hexbytes`", err)
+		return nil
+	}
+	// Decode to a separate buffer, since tr.scratch may contain the current row.
+	buf := tr.colBuffer(hex.DecodedLen(len(b)))
+	n, err := hex.Decode(buf, b)
+	if err != nil {
+		tr.setColError("cannot parse `hexbytes`", err)
+		return nil
+	}
+	return buf[:n]
+}
+
+// SetBase64Encoding sets the encoding for Base64Bytes,
+// e.g. base64.URLEncoding or base64.RawStdEncoding.
+//
+// Pass nil for using base64.StdEncoding. This is the default.
+func (tr *Reader) SetBase64Encoding(enc *base64.Encoding) {
+	tr.base64Enc = enc
+}
+
+// Base64Bytes returns the next base64-encoded column value from the current row
+// decoded to bytes.
+//
+// See SetBase64Encoding for the supported encodings.
+//
+// The returned value is valid until the next call to Reader.
+func (tr *Reader) Base64Bytes() []byte {
+	if tr.err != nil {
+		return nil
+	}
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read `base64bytes`", err)
+		return nil
+	}
+	enc := tr.base64Enc
+	if enc == nil {
+		enc = base64.StdEncoding
+	}
+	buf := tr.colBuffer(enc.DecodedLen(len(b)))
+	n, err := enc.Decode(buf, b)
+	if err != nil {
+		tr.setColError("cannot parse `base64bytes`", err)
+		return nil
+	}
+	return buf[:n]
+}
+
+// colBuffer returns a buffer of size n for the decoded column value.
+func (tr *Reader) colBuffer(n int) []byte {
+	if cap(tr.colBuf) < n {
+		tr.colBuf = make([]byte, n)
+	}
+	return tr.colBuf[:n]
+}
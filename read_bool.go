@@ -0,0 +1,104 @@
+package dsvreader
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	defaultTrueTokens    = []string{"1", "true", "t", "yes", "y"}
+	defaultFalseTokens   = []string{"0", "false", "f", "no", "n"}
+	defaultUnknownTokens = []string{""}
+)
+
+// SetBoolTokens sets tokens for true and false values.
+//
+// Tokens are matched case-insensitively. By default `1`, `true`, `t`,
+// `yes` and `y` are true, while `0`, `false`, `f`, `no` and `n` are false.
+func (tr *Reader) SetBoolTokens(trueTokens, falseTokens []string) {
+	tr.trueTokens = append([]string{}, trueTokens...)
+	tr.falseTokens = append([]string{}, falseTokens...)
+}
+
+// SetUnknownTokens sets tokens for unknown values read by TriBool.
+//
+// Tokens are matched case-insensitively. By default only an empty
+// column is unknown.
+func (tr *Reader) SetUnknownTokens(tokens ...string) {
+	tr.unknownTokens = append([]string{}, tokens...)
+}
+
+// Bool returns the next bool column value from the current row.
+//
+// The column must contain one of the tokens set via SetBoolTokens.
+// An empty column results in an error, use TriBool for columns
+// with missing values.
+func (tr *Reader) Bool() bool {
+	if tr.err != nil {
+		return false
+	}
+	b, ok := tr.nextTypedCol("bool")
+	if !ok {
+		return false
+	}
+	value, ok := tr.parseBool(b2s(b))
+	if !ok {
+		tr.setColError("cannot parse `bool`", fmt.Errorf("unexpected value %q", b))
+		return false
+	}
+	return value
+}
+
+// TriBool returns the next tri-state bool column value from the current row.
+//
+// known is false if the column contains one of the tokens set via
+// SetUnknownTokens. Otherwise the column must contain one of the tokens
+// set via SetBoolTokens.
+func (tr *Reader) TriBool() (value, known bool) {
+	if tr.err != nil {
+		return false, false
+	}
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read `tribool`", err)
+		return false, false
+	}
+	s := b2s(b)
+
+	unknownTokens := tr.unknownTokens
+	if unknownTokens == nil {
+		unknownTokens = defaultUnknownTokens
+	}
+	if matchToken(s, unknownTokens) {
+		return false, false
+	}
+	value, ok := tr.parseBool(s)
+	if !ok {
+		tr.setColError("cannot parse `tribool`", fmt.Errorf("unexpected value %q", s))
+		return false, false
+	}
+	return value, true
+}
+
+func (tr *Reader) parseBool(s string) (value, ok bool) {
+	trueTokens, falseTokens := tr.trueTokens, tr.falseTokens
+	if trueTokens == nil {
+		trueTokens, falseTokens = defaultTrueTokens, defaultFalseTokens
+	}
+	if matchToken(s, trueTokens) {
+		return true, true
+	}
+	if matchToken(s, falseTokens) {
+		return false, true
+	}
+	return false, false
+}
+
+func matchToken(s string, tokens []string) bool {
+	for _, token := range tokens {
+		if strings.EqualFold(s, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,7 +2,9 @@ package dsvreader
 
 import (
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -10,19 +12,36 @@ var zeroTime time.Time
 
 // Date returns the next date column value from the current row.
 //
-// date must be in the format YYYY-MM-DD
+// date must be in the format YYYY-MM-DD unless another layout is set
+// via SetDateLayout.
 func (tr *Reader) Date() time.Time {
 	if tr.err != nil {
 		return zeroTime
 	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `date`", err)
+	b, ok := tr.nextTypedCol("date")
+	if !ok {
 		return zeroTime
 	}
 	s := b2s(b)
 
+	if tr.dateLayout != "" {
+		// Slow path - parse the date with the custom layout.
+		if isZeroDate(s) {
+			// special case for ClickHouse
+			return zeroTime
+		}
+		t, err := time.ParseInLocation(tr.dateLayout, s, time.UTC)
+		if err != nil {
+			tr.setColError("cannot parse `date`", err)
+			return zeroTime
+		}
+		return t
+	}
+
 	y, m, d, err := parseDate(s)
+	if err == nil && tr.strictDates {
+		err = checkDate(y, m, d)
+	}
 	if err != nil {
 		tr.setColError("cannot parse `date`", err)
 		return zeroTime
@@ -34,21 +53,56 @@ func (tr *Reader) Date() time.Time {
 	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
 }
 
+// SetDateLayout sets the layout for Date in the format used by time.Parse,
+// e.g. `02/01/2006` for day-first dates.
+//
+// If the layout is set, Date parses all the values with time.Parse
+// instead of the fast YYYY-MM-DD parser. Values consisting of zero digits
+// and separators, such as `00/00/0000`, are returned as zero time
+// like the ClickHouse zero date `0000-00-00`.
+//
+// Pass an empty layout for restoring the default YYYY-MM-DD format.
+func (tr *Reader) SetDateLayout(layout string) {
+	tr.dateLayout = layout
+}
+
+// isZeroDate returns true if s contains zero digits and non-digit separators only.
+func isZeroDate(s string) bool {
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '0':
+			digits++
+		case c >= '1' && c <= '9':
+			return false
+		}
+	}
+	return digits > 0
+}
+
 // DateTime returns the next datetime column value from the current row.
 //
-// datetime must be in the format YYYY-MM-DD hh:mm:ss.
+// datetime must be in the format YYYY-MM-DD hh:mm:ss with optional
+// fractional seconds up to nanoseconds, e.g. `2023-01-02 03:04:05.678`.
+// It may be followed by the zone offset in the form `Z`, `±hh:mm`,
+// `±hhmm` or `±hh`, e.g. `2023-01-02 03:04:05+02:00`.
+// Datetimes without the zone offset are interpreted in the location
+// set via SetLocation or SetLocationName, UTC by default.
 func (tr *Reader) DateTime() time.Time {
 	if tr.err != nil {
 		return zeroTime
 	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `datetime`", err)
+	b, ok := tr.nextTypedCol("datetime")
+	if !ok {
 		return zeroTime
 	}
 	s := b2s(b)
 
-	dt, err := parseDateTime(s)
+	loc := tr.loc
+	if loc == nil {
+		loc = time.UTC
+	}
+	dt, err := parseDateTime(s, loc, tr.strictDates)
 	if err != nil {
 		tr.setColError("cannot parse `datetime`", err)
 		return zeroTime
@@ -56,38 +110,230 @@ func (tr *Reader) DateTime() time.Time {
 	return dt
 }
 
-func parseDateTime(s string) (time.Time, error) {
-	if len(s) != len("YYYY-MM-DD hh:mm:ss") {
+// SetLocation sets the location for the subsequent DateTime calls.
+// nil means UTC.
+//
+// The location applies only to datetimes without the zone offset.
+func (tr *Reader) SetLocation(loc *time.Location) {
+	tr.loc = loc
+}
+
+// SetLocationName sets the location for the subsequent DateTime calls
+// by its IANA Time Zone database name, e.g. `Europe/Berlin`.
+// Empty name means UTC.
+//
+// This allows reading datetimes with per-row time zones stored
+// in a separate column:
+//
+//	tr.SetLocationName(tr.String())
+//	dt := tr.DateTime()
+//
+// Unknown name results in an error on the current column.
+func (tr *Reader) SetLocationName(name string) {
+	if tr.err != nil {
+		return
+	}
+	loc, ok := tr.locCache[name]
+	if !ok {
+		var err error
+		loc, err = time.LoadLocation(name)
+		if err != nil {
+			tr.setColError("cannot load location", err)
+			return
+		}
+		if tr.locCache == nil {
+			tr.locCache = make(map[string]*time.Location)
+		}
+		tr.locCache[name] = loc
+	}
+	tr.SetLocation(loc)
+}
+
+func parseDateTime(s string, loc *time.Location, strict bool) (time.Time, error) {
+	if len(s) < len("YYYY-MM-DD hh:mm:ss") {
 		return zeroTime, fmt.Errorf("too short datetime")
 	}
+	tail := s[len("YYYY-MM-DD hh:mm:ss"):]
+	s = s[:len("YYYY-MM-DD hh:mm:ss")]
+	nsec := 0
+	if len(tail) > 0 && tail[0] == '.' {
+		n := 1
+		for n < len(tail) && tail[n] >= '0' && tail[n] <= '9' {
+			n++
+		}
+		var err error
+		nsec, err = parseFraction(tail[:n])
+		if err != nil {
+			return zeroTime, err
+		}
+		tail = tail[n:]
+	}
+	if len(tail) > 0 {
+		var err error
+		loc, err = parseZoneOffset(tail)
+		if err != nil {
+			return zeroTime, err
+		}
+	}
 	y, m, d, err := parseDate(s[:len("YYYY-MM-DD")])
 	if err != nil {
 		return zeroTime, err
 	}
 	s = s[len("YYYY-MM-DD"):]
-	if s[0] != ' ' || s[3] != ':' || s[6] != ':' {
+	if s[0] != ' ' {
 		return zeroTime, fmt.Errorf("invalid time format. Must be hh:mm:ss")
 	}
-	hS := s[1:3]
-	minS := s[4:6]
-	secS := s[7:]
-	h, err := strconv.Atoi(hS)
+	h, min, sec, err := parseClock(s[1:])
+	if err != nil {
+		return zeroTime, err
+	}
+	if strict {
+		if err := checkDate(y, m, d); err != nil {
+			return zeroTime, err
+		}
+		if err := checkTime(h, min, sec); err != nil {
+			return zeroTime, err
+		}
+	}
+	if y == 0 && m == 0 && d == 0 {
+		// Special case for ClickHouse
+		return zeroTime, nil
+	}
+	return time.Date(y, time.Month(m), d, h, min, sec, nsec, loc), nil
+}
+
+// parseZoneOffset parses the zone offset in the form `Z`, `±hh:mm`,
+// `±hhmm` or `±hh`.
+func parseZoneOffset(s string) (*time.Location, error) {
+	if s == "Z" {
+		return time.UTC, nil
+	}
+	if s[0] != '+' && s[0] != '-' {
+		return nil, fmt.Errorf("unexpected tail %q after seconds", s)
+	}
+	z := s[1:]
+	if len(z) == len("hh:mm") && z[2] == ':' {
+		z = z[:2] + z[3:]
+	}
+	if (len(z) != len("hh") && len(z) != len("hhmm")) || !isDigits(z) {
+		return nil, fmt.Errorf("invalid zone offset %q. Must be Z, ±hh:mm, ±hhmm or ±hh", s)
+	}
+	h, _ := strconv.Atoi(z[:2])
+	min := 0
+	if len(z) > 2 {
+		min, _ = strconv.Atoi(z[2:])
+	}
+	if h > 23 || min > 59 {
+		return nil, fmt.Errorf("zone offset %q out of range", s)
+	}
+	offset := (h*60 + min) * 60
+	if s[0] == '-' {
+		offset = -offset
+	}
+	return time.FixedZone("", offset), nil
+}
+
+// parseFraction parses fractional seconds in the form `.fffffffff`
+// with up to 9 digits and returns them in nanoseconds.
+func parseFraction(s string) (int, error) {
+	s = s[1:]
+	if len(s) == 0 || len(s) > 9 || !isDigits(s) {
+		return 0, fmt.Errorf("invalid fractional seconds %q. Must contain 1 to 9 digits", s)
+	}
+	nsec := 0
+	for i := 0; i < 9; i++ {
+		nsec *= 10
+		if i < len(s) {
+			nsec += int(s[i] - '0')
+		}
+	}
+	return nsec, nil
+}
+
+func parseClock(s string) (h, min, sec int, err error) {
+	if len(s) != len("hh:mm:ss") || s[2] != ':' || s[5] != ':' {
+		err = fmt.Errorf("invalid time format. Must be hh:mm:ss")
+		return
+	}
+	h, err = strconv.Atoi(s[:2])
+	if err != nil {
+		err = fmt.Errorf("invalid hour: %s", err)
+		return
+	}
+	min, err = strconv.Atoi(s[3:5])
 	if err != nil {
-		return zeroTime, fmt.Errorf("invalid hour: %s", err)
+		err = fmt.Errorf("invalid minute: %s", err)
+		return
 	}
-	min, err := strconv.Atoi(minS)
+	sec, err = strconv.Atoi(s[6:])
 	if err != nil {
-		return zeroTime, fmt.Errorf("invalid minute: %s", err)
+		err = fmt.Errorf("invalid second: %s", err)
+		return
+	}
+	return h, min, sec, nil
+}
+
+// TimeOfDay returns the next time of day column value from the current row
+// as duration since midnight.
+//
+// The time must be in the format hh:mm:ss. Out-of-range components
+// are rejected if SetStrictDates is enabled, otherwise they are added up,
+// so `24:00:00` results in 24 hours.
+func (tr *Reader) TimeOfDay() time.Duration {
+	if tr.err != nil {
+		return 0
+	}
+	b, ok := tr.nextTypedCol("timeofday")
+	if !ok {
+		return 0
+	}
+	h, min, sec, err := parseClock(b2s(b))
+	if err == nil && tr.strictDates {
+		err = checkTime(h, min, sec)
 	}
-	sec, err := strconv.Atoi(secS)
 	if err != nil {
-		return zeroTime, fmt.Errorf("invalid second: %s", err)
+		tr.setColError("cannot parse `timeofday`", err)
+		return 0
 	}
+	return time.Duration(h)*time.Hour + time.Duration(min)*time.Minute + time.Duration(sec)*time.Second
+}
+
+// SetStrictDates controls whether Date, DateTime and TimeOfDay reject
+// out-of-range components such as `2021-02-30` or `24:00:00`.
+//
+// By default such values are normalized, e.g. `2021-02-30`
+// becomes March 2. The ClickHouse zero date `0000-00-00` is accepted
+// in both modes.
+func (tr *Reader) SetStrictDates(strict bool) {
+	tr.strictDates = strict
+}
+
+func checkDate(y, m, d int) error {
 	if y == 0 && m == 0 && d == 0 {
-		// Special case for ClickHouse
-		return zeroTime, nil
+		return nil
+	}
+	if m < 1 || m > 12 {
+		return fmt.Errorf("month %d out of range [1..12]", m)
+	}
+	// Day 0 of the next month is the last day of the month.
+	days := time.Date(y, time.Month(m)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if d < 1 || d > days {
+		return fmt.Errorf("day %d out of range [1..%d]", d, days)
+	}
+	return nil
+}
+
+func checkTime(h, min, sec int) error {
+	if h < 0 || h > 23 {
+		return fmt.Errorf("hour %d out of range [0..23]", h)
+	}
+	if min < 0 || min > 59 {
+		return fmt.Errorf("minute %d out of range [0..59]", min)
+	}
+	if sec < 0 || sec > 59 {
+		return fmt.Errorf("second %d out of range [0..59]", sec)
 	}
-	return time.Date(y, time.Month(m), d, h, min, sec, 0, time.UTC), nil
+	return nil
 }
 
 func parseDate(s string) (y, m, d int, err error) {
@@ -120,3 +366,280 @@ func parseDate(s string) (y, m, d int, err error) {
 	}
 	return y, m, d, nil
 }
+
+// UnixTime returns the next column value from the current row
+// as time for Unix timestamp in seconds, e.g. `1614834367`.
+//
+// Negative timestamps are before 1970.
+func (tr *Reader) UnixTime() time.Time {
+	if tr.err != nil {
+		return zeroTime
+	}
+	b, ok := tr.nextTypedCol("unixtime")
+	if !ok {
+		return zeroTime
+	}
+	n, err := strconv.ParseInt(b2s(b), 10, 64)
+	if err != nil {
+		tr.setColError("cannot parse `unixtime`", err)
+		return zeroTime
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+// UnixMilliTime returns the next column value from the current row
+// as time for Unix timestamp in milliseconds, e.g. `1614834367123`.
+//
+// Negative timestamps are before 1970.
+func (tr *Reader) UnixMilliTime() time.Time {
+	if tr.err != nil {
+		return zeroTime
+	}
+	b, ok := tr.nextTypedCol("unixmillitime")
+	if !ok {
+		return zeroTime
+	}
+	n, err := strconv.ParseInt(b2s(b), 10, 64)
+	if err != nil {
+		tr.setColError("cannot parse `unixmillitime`", err)
+		return zeroTime
+	}
+	return time.Unix(n/1e3, (n%1e3)*1e6).UTC()
+}
+
+// UnixFloatTime returns the next column value from the current row
+// as time for Unix timestamp in seconds with optional fractional part,
+// e.g. `1614834367.123`.
+//
+// The timestamp is parsed without rounding errors up to nanoseconds.
+// Digits beyond nanoseconds are ignored.
+func (tr *Reader) UnixFloatTime() time.Time {
+	if tr.err != nil {
+		return zeroTime
+	}
+	b, ok := tr.nextTypedCol("unixfloattime")
+	if !ok {
+		return zeroTime
+	}
+	sec, nsec, err := parseUnixFloat(b2s(b))
+	if err != nil {
+		tr.setColError("cannot parse `unixfloattime`", err)
+		return zeroTime
+	}
+	return time.Unix(sec, nsec).UTC()
+}
+
+// Duration returns the next duration column value from the current row.
+//
+// The duration must be in the format accepted by time.ParseDuration,
+// e.g. `1h30m`, `250ms` or `-1.5s`. Use DurationSeconds for durations
+// stored as plain numbers of seconds.
+func (tr *Reader) Duration() time.Duration {
+	if tr.err != nil {
+		return 0
+	}
+	b, ok := tr.nextTypedCol("duration")
+	if !ok {
+		return 0
+	}
+	d, err := time.ParseDuration(b2s(b))
+	if err != nil {
+		tr.setColError("cannot parse `duration`", err)
+		return 0
+	}
+	return d
+}
+
+// DurationSeconds returns the next column value from the current row
+// as duration for float number of seconds, e.g. `1.5` or `-0.25`.
+//
+// The duration is rounded to nanoseconds.
+func (tr *Reader) DurationSeconds() time.Duration {
+	if tr.err != nil {
+		return 0
+	}
+	b, ok := tr.nextTypedCol("durationseconds")
+	if !ok {
+		return 0
+	}
+	f, err := strconv.ParseFloat(b2s(b), 64)
+	if err != nil {
+		tr.setColError("cannot parse `durationseconds`", err)
+		return 0
+	}
+	ns := math.Round(f * float64(time.Second))
+	if math.IsNaN(ns) || ns < math.MinInt64 || ns >= math.MaxInt64 {
+		tr.setColError("cannot parse `durationseconds`", fmt.Errorf("duration out of range"))
+		return 0
+	}
+	return time.Duration(ns)
+}
+
+// SetISODurationApprox controls whether ISODuration accepts years
+// and months, which have no fixed length.
+//
+// If enabled, a year is approximated by 365 days and a month
+// is approximated by 30 days. By default years and months result in an error.
+func (tr *Reader) SetISODurationApprox(approx bool) {
+	tr.isoDurationApprox = approx
+}
+
+// ISODuration returns the next column value from the current row
+// as duration in ISO 8601 format, e.g. `PT1H30M`, `P1DT2H` or `-PT0.5S`.
+//
+// Days are 24 hours and weeks are 7 days. Only seconds may have
+// a fractional part. See SetISODurationApprox for years and months.
+func (tr *Reader) ISODuration() time.Duration {
+	if tr.err != nil {
+		return 0
+	}
+	b, ok := tr.nextTypedCol("isoduration")
+	if !ok {
+		return 0
+	}
+	d, err := parseISODuration(b2s(b), tr.isoDurationApprox)
+	if err != nil {
+		tr.setColError("cannot parse `isoduration`", err)
+		return 0
+	}
+	return d
+}
+
+const day = 24 * time.Hour
+
+func parseISODuration(s string, approx bool) (time.Duration, error) {
+	orig := s
+	neg := false
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if len(s) == 0 || s[0] != 'P' {
+		return 0, fmt.Errorf("missing `P` designator in %q", orig)
+	}
+	s = s[1:]
+
+	var d time.Duration
+	inTime := false
+	components := 0
+	for len(s) > 0 {
+		if s[0] == 'T' {
+			if inTime {
+				return 0, fmt.Errorf("duplicate `T` designator in %q", orig)
+			}
+			inTime = true
+			s = s[1:]
+			if len(s) == 0 {
+				return 0, fmt.Errorf("missing time components after `T` in %q", orig)
+			}
+			continue
+		}
+
+		n := 0
+		for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+		intS, fracS := s[:n], ""
+		if n < len(s) && (s[n] == '.' || s[n] == ',') {
+			m := n + 1
+			for m < len(s) && s[m] >= '0' && s[m] <= '9' {
+				m++
+			}
+			fracS = s[n+1 : m]
+			if len(fracS) == 0 {
+				return 0, fmt.Errorf("missing fractional part in %q", orig)
+			}
+			n = m
+		}
+		if len(intS) == 0 {
+			return 0, fmt.Errorf("missing number in %q", orig)
+		}
+		if n == len(s) {
+			return 0, fmt.Errorf("missing unit designator in %q", orig)
+		}
+		designator := s[n]
+		s = s[n+1:]
+
+		var unit time.Duration
+		switch {
+		case !inTime && designator == 'Y' && approx:
+			unit = 365 * day
+		case !inTime && designator == 'M' && approx:
+			unit = 30 * day
+		case !inTime && (designator == 'Y' || designator == 'M'):
+			return 0, fmt.Errorf("years and months have no fixed duration in %q", orig)
+		case !inTime && designator == 'W':
+			unit = 7 * day
+		case !inTime && designator == 'D':
+			unit = day
+		case inTime && designator == 'H':
+			unit = time.Hour
+		case inTime && designator == 'M':
+			unit = time.Minute
+		case inTime && designator == 'S':
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("unexpected unit designator %q in %q", designator, orig)
+		}
+		if len(fracS) > 0 && (unit != time.Second || len(s) > 0) {
+			return 0, fmt.Errorf("only trailing seconds may have fractional part in %q", orig)
+		}
+
+		v, err := strconv.ParseInt(intS, 10, 64)
+		if err != nil || v > int64(math.MaxInt64/unit) {
+			return 0, fmt.Errorf("duration overflow in %q", orig)
+		}
+		var frac time.Duration
+		for i := 0; i < 9; i++ {
+			frac *= 10
+			if i < len(fracS) {
+				frac += time.Duration(fracS[i] - '0')
+			}
+		}
+		c := time.Duration(v)*unit + frac
+		if d > math.MaxInt64-c {
+			return 0, fmt.Errorf("duration overflow in %q", orig)
+		}
+		d += c
+		components++
+	}
+	if components == 0 {
+		return 0, fmt.Errorf("missing duration components in %q", orig)
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+func parseUnixFloat(s string) (sec, nsec int64, err error) {
+	neg := false
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	secS, fracS := s, ""
+	if n := strings.IndexByte(s, '.'); n >= 0 {
+		secS, fracS = s[:n], s[n+1:]
+		if len(fracS) == 0 {
+			return 0, 0, fmt.Errorf("missing fractional part")
+		}
+	}
+	if !isDigits(secS) || len(fracS) > 0 && !isDigits(fracS) {
+		return 0, 0, fmt.Errorf("invalid syntax")
+	}
+	sec, err = strconv.ParseInt(secS, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	for i := 0; i < 9; i++ {
+		nsec *= 10
+		if i < len(fracS) {
+			nsec += int64(fracS[i] - '0')
+		}
+	}
+	if neg {
+		sec, nsec = -sec, -nsec
+	}
+	return sec, nsec, nil
+}
@@ -0,0 +1,77 @@
+package dsvreader
+
+import (
+	"bytes"
+	"net/netip"
+	"net/url"
+)
+
+// URL returns the next URL column value from the current row.
+//
+// The column is unescaped the same way as Bytes does before parsing
+// with url.Parse. nil is returned on error.
+func (tr *Reader) URL() *url.URL {
+	b := tr.Bytes()
+	if tr.err != nil {
+		return nil
+	}
+	u, err := url.Parse(string(b))
+	if err != nil {
+		tr.setColError("cannot parse `url`", err)
+		return nil
+	}
+	return u
+}
+
+// IP returns the next IP address column value from the current row,
+// e.g. `192.168.0.1`, `2001:db8::1` or `fe80::1%eth0`.
+//
+// The zero netip.Addr is returned on error.
+func (tr *Reader) IP() netip.Addr {
+	if tr.err != nil {
+		return netip.Addr{}
+	}
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read `ip`", err)
+		return netip.Addr{}
+	}
+	addr, err := netip.ParseAddr(netString(b))
+	if err != nil {
+		tr.setColError("cannot parse `ip`", err)
+		return netip.Addr{}
+	}
+	return addr
+}
+
+// Prefix returns the next IP prefix column value from the current row
+// in the CIDR notation, e.g. `192.168.0.0/16` or `2001:db8::/32`.
+//
+// The zero netip.Prefix is returned on error.
+func (tr *Reader) Prefix() netip.Prefix {
+	if tr.err != nil {
+		return netip.Prefix{}
+	}
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read `prefix`", err)
+		return netip.Prefix{}
+	}
+	prefix, err := netip.ParsePrefix(netString(b))
+	if err != nil {
+		tr.setColError("cannot parse `prefix`", err)
+		return netip.Prefix{}
+	}
+	return prefix
+}
+
+// netString returns b as a string for parsing with net/netip.
+//
+// IPv6 zones are retained by the parsed addresses, so b is copied
+// if it contains a zone.
+func netString(b []byte) string {
+	if bytes.IndexByte(b, '%') >= 0 {
+		return string(b)
+	}
+	return b2s(b)
+}
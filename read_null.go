@@ -0,0 +1,217 @@
+package dsvreader
+
+import (
+	"fmt"
+	"strconv"
+)
+
+var defaultNullToken = []byte(`\N`)
+
+// SetNullToken sets the token for NULL values read by Nullable* readers.
+//
+// The token is compared with the raw column before unescaping,
+// so the default `\N` token matches ClickHouse NULL values.
+// Empty columns are always NULL, while quoted columns are never NULL.
+func (tr *Reader) SetNullToken(token string) {
+	tr.nullToken = append([]byte{}, token...)
+}
+
+// SetTreatNullAsZero controls whether typed readers return zero values
+// without errors for NULL values, see SetNullToken.
+//
+// This applies to integer, float, complex and bool readers including
+// IntN, Float64N, BoolInt, Bit, Percent, Point, Money, Decimal,
+// ImpliedDecimal, BigInt and BigFloat, as well as to Date, DateTime,
+// TimeOfDay, UnixTime, UnixMilliTime, UnixFloatTime, Duration,
+// DurationSeconds and ISODuration.
+// BigInt and BigFloat return zero numbers rather than nil. IntRange results
+// in an error if zero is out of range. Note that empty columns are NULL too.
+// Disabled by default, so NULL values result in parse errors.
+func (tr *Reader) SetTreatNullAsZero(nullAsZero bool) {
+	tr.nullAsZero = nullAsZero
+}
+
+// nextTypedCol reads the next column for the typed reader of the given type.
+//
+// This is the pre-parse step shared by typed readers. ok is false if the column
+// cannot be read or if it is NULL while SetTreatNullAsZero is enabled.
+// The reader must return the zero value in both cases. The error is set
+// only in the former case.
+func (tr *Reader) nextTypedCol(typ string) (b []byte, ok bool) {
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read `"+typ+"`", err)
+		return nil, false
+	}
+	if tr.nullAsZero && tr.isNull(b) {
+		return nil, false
+	}
+	return b, true
+}
+
+// isNull returns true if the column b read by nextCol is NULL.
+func (tr *Reader) isNull(b []byte) bool {
+	if tr.colQuoted {
+		return false
+	}
+	if len(b) == 0 {
+		return true
+	}
+	token := tr.nullToken
+	if token == nil {
+		token = defaultNullToken
+	}
+	return string(b) == string(token)
+}
+
+// NullableBytes returns the next nullable bytes column value from the current row.
+//
+// ok is false for NULL values set via SetNullToken. No error is set
+// in this case.
+//
+// The returned value is valid until the next call to Reader.
+func (tr *Reader) NullableBytes() (b []byte, ok bool) {
+	if tr.err != nil {
+		return nil, false
+	}
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read `bytes`", err)
+		return nil, false
+	}
+	if tr.isNull(b) {
+		return nil, false
+	}
+	b = tr.decodeBytes(b)
+	return b, tr.err == nil
+}
+
+// NullableString returns the next nullable string column value from the current row.
+//
+// ok is false for NULL values set via SetNullToken. No error is set
+// in this case.
+func (tr *Reader) NullableString() (s string, ok bool) {
+	b, ok := tr.NullableBytes()
+	return string(b), ok
+}
+
+// NullableInt returns the next nullable int column value from the current row.
+//
+// ok is false for NULL values set via SetNullToken. No error is set
+// in this case.
+func (tr *Reader) NullableInt() (n int, ok bool) {
+	if tr.err != nil {
+		return 0, false
+	}
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read `int`", err)
+		return 0, false
+	}
+	if tr.isNull(b) {
+		return 0, false
+	}
+	n, err = strconv.Atoi(b2s(b))
+	if err != nil {
+		tr.setColError("cannot parse `int`", err)
+		return 0, false
+	}
+	return n, true
+}
+
+// NullableInt64 returns the next nullable int64 column value from the current row.
+//
+// ok is false for NULL values set via SetNullToken. No error is set
+// in this case.
+func (tr *Reader) NullableInt64() (n int64, ok bool) {
+	if tr.err != nil {
+		return 0, false
+	}
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read `int64`", err)
+		return 0, false
+	}
+	if tr.isNull(b) {
+		return 0, false
+	}
+	n, err = strconv.ParseInt(b2s(b), 10, 64)
+	if err != nil {
+		tr.setColError("cannot parse `int64`", err)
+		return 0, false
+	}
+	return n, true
+}
+
+// NullableUint64 returns the next nullable uint64 column value from the current row.
+//
+// ok is false for NULL values set via SetNullToken. No error is set
+// in this case.
+func (tr *Reader) NullableUint64() (n uint64, ok bool) {
+	if tr.err != nil {
+		return 0, false
+	}
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read `uint64`", err)
+		return 0, false
+	}
+	if tr.isNull(b) {
+		return 0, false
+	}
+	s := b2s(b)
+	n, err = strconv.ParseUint(s, tr.uintBase(s), 64)
+	if err != nil {
+		tr.setColError("cannot parse `uint64`", err)
+		return 0, false
+	}
+	return n, true
+}
+
+// NullableFloat64 returns the next nullable float64 column value from the current row.
+//
+// ok is false for NULL values set via SetNullToken. No error is set
+// in this case.
+func (tr *Reader) NullableFloat64() (f float64, ok bool) {
+	if tr.err != nil {
+		return 0, false
+	}
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read `float64`", err)
+		return 0, false
+	}
+	if tr.isNull(b) {
+		return 0, false
+	}
+	f, err = tr.parseFloat(b, 64, &tr.colBuf)
+	if err != nil {
+		tr.setColError("cannot parse `float64`", err)
+		return 0, false
+	}
+	return f, true
+}
+
+// NullableBool returns the next nullable bool column value from the current row.
+//
+// ok is false for NULL values set via SetNullToken. No error is set
+// in this case.
+func (tr *Reader) NullableBool() (v, ok bool) {
+	if tr.err != nil {
+		return false, false
+	}
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read `bool`", err)
+		return false, false
+	}
+	if tr.isNull(b) {
+		return false, false
+	}
+	v, ok = tr.parseBool(b2s(b))
+	if !ok {
+		tr.setColError("cannot parse `bool`", fmt.Errorf("unexpected value %q", b))
+		return false, false
+	}
+	return v, true
+}
@@ -1,9 +1,12 @@
 package dsvreader
 
 import (
+	"bytes"
 	"fmt"
 	"math"
+	"math/big"
 	"strconv"
+	"strings"
 )
 
 // Int returns the next int column value from the current row.
@@ -11,9 +14,8 @@ func (tr *Reader) Int() int {
 	if tr.err != nil {
 		return 0
 	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `int`", err)
+	b, ok := tr.nextTypedCol("int")
+	if !ok {
 		return 0
 	}
 
@@ -25,14 +27,43 @@ func (tr *Reader) Int() int {
 	return n
 }
 
+// SetUintAutoBase controls whether unsigned integer readers accept
+// base prefixes: `0x` for hex, `0o` for octal and `0b` for binary values.
+// Underscores are permitted as digit separators in this mode
+// as defined by Go syntax for integer literals.
+//
+// Values without prefix are parsed as decimal, so `017` is 17.
+// They are parsed as fast as without the prefix support.
+// Underscores aren't permitted in values with leading zeros.
+func (tr *Reader) SetUintAutoBase(autoBase bool) {
+	tr.uintAutoBase = autoBase
+}
+
+// uintBase returns the base for parsing s with strconv.ParseUint.
+//
+// Base 0 parses values with a leading zero as octal, so they are parsed
+// as decimal.
+func (tr *Reader) uintBase(s string) int {
+	if !tr.uintAutoBase {
+		return 10
+	}
+	if len(s) > 1 && s[0] == '0' {
+		switch s[1] {
+		case 'x', 'X', 'o', 'O', 'b', 'B':
+			return 0
+		}
+		return 10
+	}
+	return 0
+}
+
 // Uint returns the next uint column value from the current row.
 func (tr *Reader) Uint() uint {
 	if tr.err != nil {
 		return 0
 	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `uint`", err)
+	b, ok := tr.nextTypedCol("uint")
+	if !ok {
 		return 0
 	}
 	s := b2s(b)
@@ -44,7 +75,7 @@ func (tr *Reader) Uint() uint {
 	}
 
 	// Slow path - use ParseUint
-	nu, err := strconv.ParseUint(s, 10, strconv.IntSize)
+	nu, err := strconv.ParseUint(s, tr.uintBase(s), strconv.IntSize)
 	if err != nil {
 		tr.setColError("cannot parse `uint`", err)
 		return 0
@@ -57,9 +88,8 @@ func (tr *Reader) Int32() int32 {
 	if tr.err != nil {
 		return 0
 	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `int32`", err)
+	b, ok := tr.nextTypedCol("int32")
+	if !ok {
 		return 0
 	}
 	s := b2s(b)
@@ -79,14 +109,53 @@ func (tr *Reader) Int32() int32 {
 	return int32(n32)
 }
 
+// IntRange returns the next int64 column value from the current row
+// and verifies it is in the range [min..max], e.g. [100..599] for HTTP
+// status codes.
+//
+// Values outside the range result in an error.
+func (tr *Reader) IntRange(min, max int64) int64 {
+	return tr.intRange("int64", min, max)
+}
+
+func (tr *Reader) intRange(typ string, min, max int64) int64 {
+	if tr.err != nil {
+		return 0
+	}
+	b, ok := tr.nextTypedCol(typ)
+	if !ok {
+		if tr.err == nil && (min > 0 || max < 0) {
+			tr.setColError("cannot parse `"+typ+"`", fmt.Errorf("NULL read as 0 is out of range [%d..%d]", min, max))
+		}
+		return 0
+	}
+	s := b2s(b)
+
+	// Fast path - attempt to use Atoi
+	n, err := strconv.Atoi(s)
+	n64 := int64(n)
+	if err != nil {
+		// Slow path - use ParseInt
+		n64, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			tr.setColError("cannot parse `"+typ+"`", err)
+			return 0
+		}
+	}
+	if n64 < min || n64 > max {
+		tr.setColError("cannot parse `"+typ+"`", fmt.Errorf("%d out of range [%d..%d]", n64, min, max))
+		return 0
+	}
+	return n64
+}
+
 // Uint32 returns the next uint32 column value from the current row.
 func (tr *Reader) Uint32() uint32 {
 	if tr.err != nil {
 		return 0
 	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `uint32`", err)
+	b, ok := tr.nextTypedCol("uint32")
+	if !ok {
 		return 0
 	}
 	s := b2s(b)
@@ -98,7 +167,7 @@ func (tr *Reader) Uint32() uint32 {
 	}
 
 	// Slow path - use ParseUint
-	n32, err := strconv.ParseUint(s, 10, 32)
+	n32, err := strconv.ParseUint(s, tr.uintBase(s), 32)
 	if err != nil {
 		tr.setColError("cannot parse `uint32`", err)
 		return 0
@@ -108,24 +177,7 @@ func (tr *Reader) Uint32() uint32 {
 
 // Int16 returns the next int16 column value from the current row.
 func (tr *Reader) Int16() int16 {
-	if tr.err != nil {
-		return 0
-	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `int16`", err)
-		return 0
-	}
-	n, err := strconv.Atoi(b2s(b))
-	if err != nil {
-		tr.setColError("cannot parse `int16`", err)
-		return 0
-	}
-	if n < math.MinInt16 || n > math.MaxInt16 {
-		tr.setColError("cannot parse `int16`", fmt.Errorf("out of range"))
-		return 0
-	}
-	return int16(n)
+	return int16(tr.intRange("int16", math.MinInt16, math.MaxInt16))
 }
 
 // Uint16 returns the next uint16 column value from the current row.
@@ -133,12 +185,19 @@ func (tr *Reader) Uint16() uint16 {
 	if tr.err != nil {
 		return 0
 	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `uint16`", err)
+	b, ok := tr.nextTypedCol("uint16")
+	if !ok {
 		return 0
 	}
-	n, err := strconv.Atoi(b2s(b))
+	s := b2s(b)
+
+	n, err := strconv.Atoi(s)
+	if err != nil && tr.uintAutoBase {
+		// Slow path - use ParseUint
+		var nu uint64
+		nu, err = strconv.ParseUint(s, tr.uintBase(s), 16)
+		n = int(nu)
+	}
 	if err != nil {
 		tr.setColError("cannot parse `uint16`", err)
 		return 0
@@ -156,24 +215,7 @@ func (tr *Reader) Uint16() uint16 {
 
 // Int8 returns the next int8 column value from the current row.
 func (tr *Reader) Int8() int8 {
-	if tr.err != nil {
-		return 0
-	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `int8`", err)
-		return 0
-	}
-	n, err := strconv.Atoi(b2s(b))
-	if err != nil {
-		tr.setColError("cannot parse `int8`", err)
-		return 0
-	}
-	if n < math.MinInt8 || n > math.MaxInt8 {
-		tr.setColError("cannot parse `int8`", fmt.Errorf("out of range"))
-		return 0
-	}
-	return int8(n)
+	return int8(tr.intRange("int8", math.MinInt8, math.MaxInt8))
 }
 
 // Uint8 returns the next uint8 column value from the current row.
@@ -181,12 +223,19 @@ func (tr *Reader) Uint8() uint8 {
 	if tr.err != nil {
 		return 0
 	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `uint8`", err)
+	b, ok := tr.nextTypedCol("uint8")
+	if !ok {
 		return 0
 	}
-	n, err := strconv.Atoi(b2s(b))
+	s := b2s(b)
+
+	n, err := strconv.Atoi(s)
+	if err != nil && tr.uintAutoBase {
+		// Slow path - use ParseUint
+		var nu uint64
+		nu, err = strconv.ParseUint(s, tr.uintBase(s), 8)
+		n = int(nu)
+	}
 	if err != nil {
 		tr.setColError("cannot parse `uint8`", err)
 		return 0
@@ -207,9 +256,8 @@ func (tr *Reader) Int64() int64 {
 	if tr.err != nil {
 		return 0
 	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `int64`", err)
+	b, ok := tr.nextTypedCol("int64")
+	if !ok {
 		return 0
 	}
 	s := b2s(b)
@@ -229,14 +277,46 @@ func (tr *Reader) Int64() int64 {
 	return n64
 }
 
+// IntLoose returns the next int64 column value from the current row
+// accepting integers written as floats, e.g. `1e6` or `42.0`.
+//
+// The value must be integral and fit int64, so `42.5` results in an error.
+func (tr *Reader) IntLoose() int64 {
+	if tr.err != nil {
+		return 0
+	}
+	b, ok := tr.nextTypedCol("int64")
+	if !ok {
+		return 0
+	}
+	s := b2s(b)
+
+	// Fast path - plain integer.
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err == nil {
+		return n
+	}
+
+	// Slow path - integral float.
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		tr.setColError("cannot parse `int64`", err)
+		return 0
+	}
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		tr.setColError("cannot parse `int64`", fmt.Errorf("%s isn't an integer in int64 range", s))
+		return 0
+	}
+	return int64(f)
+}
+
 // Uint64 returns the next uint64 column value from the current row.
 func (tr *Reader) Uint64() uint64 {
 	if tr.err != nil {
 		return 0
 	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `uint64`", err)
+	b, ok := tr.nextTypedCol("uint64")
+	if !ok {
 		return 0
 	}
 	s := b2s(b)
@@ -248,7 +328,7 @@ func (tr *Reader) Uint64() uint64 {
 	}
 
 	// Slow path - use ParseUint
-	n64, err := strconv.ParseUint(s, 10, 64)
+	n64, err := strconv.ParseUint(s, tr.uintBase(s), 64)
 	if err != nil {
 		tr.setColError("cannot parse `uint64`", err)
 		return 0
@@ -256,19 +336,131 @@ func (tr *Reader) Uint64() uint64 {
 	return n64
 }
 
+// SetNaNTokens sets tokens, which are read as NaN by Float32 and Float64,
+// e.g. `NA`, `N/A` or `-`.
+//
+// By default no tokens are set, so such values result in parse errors.
+func (tr *Reader) SetNaNTokens(tokens ...string) {
+	tr.nanTokens = append(tr.nanTokens[:0], tokens...)
+}
+
+// SetNaNTokensCaseInsensitive controls whether tokens set via SetNaNTokens
+// are matched case-insensitively.
+func (tr *Reader) SetNaNTokensCaseInsensitive(caseInsensitive bool) {
+	tr.nanTokensFold = caseInsensitive
+}
+
+func (tr *Reader) isNaNToken(b []byte) bool {
+	for _, token := range tr.nanTokens {
+		if len(token) != len(b) {
+			continue
+		}
+		if b2s(b) == token || tr.nanTokensFold && strings.EqualFold(b2s(b), token) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRejectNonFinite controls whether float readers such as Float64,
+// Float64N and NullableFloat64 result in errors for NaN and infinite
+// values, e.g. `NaN` or `-Inf`.
+//
+// Tokens set via SetNaNTokens are still read as NaN.
+// Disabled by default.
+func (tr *Reader) SetRejectNonFinite(reject bool) {
+	tr.rejectNonFinite = reject
+}
+
+// SetDecimalSeparator sets the decimal separator for float readers
+// such as Float64, Float64N and NullableFloat64, e.g. `,` for reading
+// `3,14` as 3.14.
+//
+// The separator mustn't match the column separator, since such columns
+// cannot be split. Reading floats results in an error in this case.
+// Pass 0 for restoring the default `.`.
+func (tr *Reader) SetDecimalSeparator(sep byte) {
+	tr.decimalSep = sep
+}
+
+// SetThousandsSeparator sets the digit grouping char, which is skipped
+// by float readers, e.g. `.` for reading `1.234,56` as 1234.56
+// with the `,` decimal separator.
+//
+// The separator mustn't match the column separator or the decimal separator.
+// Pass 0 for disabling digit grouping. This is the default.
+func (tr *Reader) SetThousandsSeparator(sep byte) {
+	tr.thousandsSep = sep
+}
+
+// parseFloat parses the float column b with the given bit size.
+//
+// It is shared by all the float readers. buf is used for translating
+// localized floats, see SetDecimalSeparator. parseFloat doesn't modify tr,
+// so it may be called concurrently with distinct bufs.
+func (tr *Reader) parseFloat(b []byte, bitSize int, buf *[]byte) (float64, error) {
+	if tr.isNaNToken(b) {
+		return math.NaN(), nil
+	}
+	if tr.decimalSep != 0 || tr.thousandsSep != 0 {
+		// Translate into a separate buffer, since b may point to the row shared
+		// with RawLine and ReadAt.
+		var err error
+		*buf, err = tr.localizeFloat((*buf)[:0], b)
+		if err != nil {
+			return 0, err
+		}
+		b = *buf
+	}
+	f, err := strconv.ParseFloat(b2s(b), bitSize)
+	if err != nil {
+		return 0, err
+	}
+	if tr.rejectNonFinite && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return 0, fmt.Errorf("non-finite value %q", b)
+	}
+	return f, nil
+}
+
+// localizeFloat appends b translated with the separators set via
+// SetDecimalSeparator and SetThousandsSeparator to dst in the form accepted
+// by strconv.ParseFloat.
+func (tr *Reader) localizeFloat(dst, b []byte) ([]byte, error) {
+	decimalSep, thousandsSep := tr.decimalSep, tr.thousandsSep
+	if decimalSep == 0 {
+		decimalSep = '.'
+	}
+	if decimalSep == tr.sep || thousandsSep == tr.sep {
+		return dst, fmt.Errorf("number separators conflict with column separator %q", tr.sep)
+	}
+	if decimalSep == thousandsSep {
+		return dst, fmt.Errorf("decimal separator %q conflicts with thousands separator", decimalSep)
+	}
+
+	for _, c := range b {
+		switch {
+		case thousandsSep != 0 && c == thousandsSep:
+			continue
+		case c == decimalSep:
+			c = '.'
+		case c == '.':
+			return dst, fmt.Errorf("unexpected %q in %q", c, b)
+		}
+		dst = append(dst, c)
+	}
+	return dst, nil
+}
+
 // Float32 returns the next float32 column value from the current row.
 func (tr *Reader) Float32() float32 {
 	if tr.err != nil {
 		return 0
 	}
-	b, err := tr.nextCol()
-	if err != nil {
-		tr.setColError("cannot read `float32`", err)
+	b, ok := tr.nextTypedCol("float32")
+	if !ok {
 		return 0
 	}
-	s := b2s(b)
-
-	f32, err := strconv.ParseFloat(s, 32)
+	f32, err := tr.parseFloat(b, 32, &tr.colBuf)
 	if err != nil {
 		tr.setColError("cannot parse `float32`", err)
 		return 0
@@ -278,6 +470,28 @@ func (tr *Reader) Float32() float32 {
 
 // Float64 returns the next float64 column value from the current row.
 func (tr *Reader) Float64() float64 {
+	if tr.err != nil {
+		return 0
+	}
+	b, ok := tr.nextTypedCol("float64")
+	if !ok {
+		return 0
+	}
+	f64, err := tr.parseFloat(b, 64, &tr.colBuf)
+	if err != nil {
+		tr.setColError("cannot parse `float64`", err)
+		return 0
+	}
+	return f64
+}
+
+// Float64Or returns the next float64 column value from the current row
+// or dflt if the value is NULL, unparseable or isn't finite.
+// See SetNullToken for NULL values.
+//
+// Values matching tokens set via SetNaNTokens result in dflt too.
+// Errors are set only if the column cannot be read.
+func (tr *Reader) Float64Or(dflt float64) float64 {
 	if tr.err != nil {
 		return 0
 	}
@@ -286,12 +500,560 @@ func (tr *Reader) Float64() float64 {
 		tr.setColError("cannot read `float64`", err)
 		return 0
 	}
+	if tr.isNull(b) {
+		return dflt
+	}
+	f64, err := tr.parseFloat(b, 64, &tr.colBuf)
+	if err != nil || math.IsNaN(f64) || math.IsInf(f64, 0) {
+		return dflt
+	}
+	return f64
+}
+
+// Complex64 returns the next complex64 column value from the current row.
+//
+// See Complex128 for the supported formats.
+func (tr *Reader) Complex64() complex64 {
+	if tr.err != nil {
+		return 0
+	}
+	b, ok := tr.nextTypedCol("complex64")
+	if !ok {
+		return 0
+	}
+	c, err := strconv.ParseComplex(b2s(b), 64)
+	if err != nil {
+		tr.setColError("cannot parse `complex64`", err)
+		return 0
+	}
+	return complex64(c)
+}
+
+// Complex128 returns the next complex128 column value from the current row.
+//
+// The value must be in the form accepted by strconv.ParseComplex,
+// e.g. `1.5+2.3i`, `(1.5+2.3i)`, `1.5` or `2.3i`.
+func (tr *Reader) Complex128() complex128 {
+	if tr.err != nil {
+		return 0
+	}
+	b, ok := tr.nextTypedCol("complex128")
+	if !ok {
+		return 0
+	}
+	c, err := strconv.ParseComplex(b2s(b), 128)
+	if err != nil {
+		tr.setColError("cannot parse `complex128`", err)
+		return 0
+	}
+	return c
+}
+
+// BigInt returns the next big.Int column value from the current row.
+//
+// It may be used for integers exceeding the int64 range.
+// nil is returned on error.
+func (tr *Reader) BigInt() *big.Int {
+	if tr.err != nil {
+		return nil
+	}
+	b, ok := tr.nextTypedCol("bigint")
+	if !ok {
+		if tr.err != nil {
+			return nil
+		}
+		return new(big.Int)
+	}
+	n, ok := new(big.Int).SetString(b2s(b), 10)
+	if !ok {
+		tr.setColError("cannot parse `bigint`", fmt.Errorf("invalid syntax"))
+		return nil
+	}
+	return n
+}
+
+// BigFloat returns the next big.Float column value from the current row.
+//
+// The precision is chosen so that all the decimal digits of the value
+// are preserved, but it is at least 64 bits. nil is returned on error.
+func (tr *Reader) BigFloat() *big.Float {
+	if tr.err != nil {
+		return nil
+	}
+	b, ok := tr.nextTypedCol("bigfloat")
+	if !ok {
+		if tr.err != nil {
+			return nil
+		}
+		return new(big.Float)
+	}
+	prec := uint(len(b)) * 4
+	if prec < 64 {
+		prec = 64
+	}
+	f, _, err := big.ParseFloat(b2s(b), 10, prec, big.ToNearestEven)
+	if err != nil {
+		tr.setColError("cannot parse `bigfloat`", err)
+		return nil
+	}
+	return f
+}
+
+// BoolInt returns the next bool column value from the current row.
+//
+// The column must contain either 0 or 1.
+func (tr *Reader) BoolInt() bool {
+	if tr.err != nil {
+		return false
+	}
+	b, ok := tr.nextTypedCol("boolint")
+	if !ok {
+		return false
+	}
+	n, err := strconv.Atoi(b2s(b))
+	if err != nil {
+		tr.setColError("cannot parse `boolint`", err)
+		return false
+	}
+	switch n {
+	case 0:
+		return false
+	case 1:
+		return true
+	default:
+		tr.setColError("cannot parse `boolint`", fmt.Errorf("must be 0 or 1"))
+		return false
+	}
+}
+
+// Money returns the next money column value from the current row.
+//
+// The column must be in the format CUR<kvSep>AMOUNT, e.g. `USD:1234.56`,
+// where CUR is an ISO 4217 currency code and AMOUNT is a decimal number
+// with up to 9 fractional digits.
+//
+// The amount is returned as whole units plus nano units of the currency.
+// nanos has the same sign as units.
+func (tr *Reader) Money(kvSep byte) (currency string, units int64, nanos int32, err error) {
+	if tr.err != nil {
+		return "", 0, 0, tr.err
+	}
+	b, ok := tr.nextTypedCol("money")
+	if !ok {
+		return "", 0, 0, tr.err
+	}
+
+	n := bytes.IndexByte(b, kvSep)
+	if n < 0 {
+		tr.setColError("cannot parse `money`", fmt.Errorf("missing %q between currency and amount", kvSep))
+		return "", 0, 0, tr.err
+	}
+	cur := b[:n]
+	if !isCurrencyCode(cur) {
+		tr.setColError("cannot parse `money`", fmt.Errorf("invalid currency code %q", cur))
+		return "", 0, 0, tr.err
+	}
+	units, nanos, err = parseMoneyAmount(b2s(b[n+1:]))
+	if err != nil {
+		tr.setColError("cannot parse `money`", err)
+		return "", 0, 0, tr.err
+	}
+	return string(cur), units, nanos, nil
+}
+
+// Decimal returns the next decimal column value from the current row
+// as mantissa and decimal exponent without rounding errors.
+//
+// The value equals to mantissa * 10^exp, e.g. `-123.4500` is returned
+// as mantissa -1234500 and exp -4, while `42` is returned as 42 and 0.
+// Trailing zeros are preserved. Values with mantissa exceeding the int64
+// range result in an error.
+func (tr *Reader) Decimal() (mantissa int64, exp int32) {
+	if tr.err != nil {
+		return 0, 0
+	}
+	b, ok := tr.nextTypedCol("decimal")
+	if !ok {
+		return 0, 0
+	}
+	mantissa, exp, err := parseDecimal(b2s(b))
+	if err != nil {
+		tr.setColError("cannot parse `decimal`", err)
+		return 0, 0
+	}
+	return mantissa, exp
+}
+
+func parseDecimal(s string) (mantissa int64, exp int32, err error) {
+	neg := false
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	intS, fracS := s, ""
+	if n := strings.IndexByte(s, '.'); n >= 0 {
+		intS, fracS = s[:n], s[n+1:]
+		if len(fracS) == 0 {
+			return 0, 0, fmt.Errorf("missing fractional part")
+		}
+	}
+	if len(intS) == 0 && len(fracS) == 0 || len(intS) > 0 && !isDigits(intS) || len(fracS) > 0 && !isDigits(fracS) {
+		return 0, 0, fmt.Errorf("invalid syntax")
+	}
+
+	var m uint64
+	for _, digits := range [2]string{intS, fracS} {
+		for i := 0; i < len(digits); i++ {
+			d := uint64(digits[i] - '0')
+			if m > (math.MaxUint64-d)/10 {
+				return 0, 0, fmt.Errorf("mantissa overflows int64")
+			}
+			m = m*10 + d
+		}
+	}
+	if !neg && m > math.MaxInt64 || neg && m > -math.MinInt64 {
+		return 0, 0, fmt.Errorf("mantissa overflows int64")
+	}
+	mantissa = int64(m)
+	if neg {
+		mantissa = -mantissa
+	}
+	return mantissa, -int32(len(fracS)), nil
+}
+
+// ImpliedDecimal returns the next column value from the current row
+// as fixed-point number with fracDigits implied fractional digits.
+//
+// The value is returned in scaled units, e.g. `12345` with fracDigits=2
+// means 123.45 and is returned as 12345. An explicit decimal point
+// is accepted too, so `123.45` and `123.4` are returned as 12345 and 12340.
+//
+// The last digit may be signed with COBOL overpunch: `{` and `A`-`I` stand
+// for positive 0-9, while `}` and `J`-`R` stand for negative 0-9,
+// e.g. `0001234{` is 12340 and `0001234}` is -12340.
+func (tr *Reader) ImpliedDecimal(fracDigits int) (int64, error) {
+	if tr.err != nil {
+		return 0, tr.err
+	}
+	b, ok := tr.nextTypedCol("implieddecimal")
+	if !ok {
+		return 0, tr.err
+	}
+	n, err := parseImpliedDecimal(b2s(b), fracDigits)
+	if err != nil {
+		tr.setColError("cannot parse `implieddecimal`", err)
+		return 0, tr.err
+	}
+	return n, nil
+}
+
+func parseImpliedDecimal(s string, fracDigits int) (int64, error) {
+	if fracDigits < 0 || fracDigits > 18 {
+		return 0, fmt.Errorf("fracDigits must be in the range [0..18]; got %d", fracDigits)
+	}
+	neg, signed := false, false
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		neg, signed = s[0] == '-', true
+		s = s[1:]
+	}
+	if len(s) == 0 {
+		return 0, fmt.Errorf("missing digits")
+	}
+
+	// Decode the overpunched last digit.
+	var last byte
+	switch c := s[len(s)-1]; {
+	case c == '{':
+		last = '0'
+	case c >= 'A' && c <= 'I':
+		last = '1' + c - 'A'
+	case c == '}':
+		last, neg = '0', true
+	case c >= 'J' && c <= 'R':
+		last, neg = '1'+c-'J', true
+	}
+	if last != 0 && signed {
+		return 0, fmt.Errorf("overpunched value cannot have explicit sign")
+	}
+
+	var buf [32]byte
+	digits := buf[:0]
+	frac := -1
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case i == len(s)-1 && last != 0:
+			c = last
+		case c == '.' && frac < 0:
+			frac = 0
+			continue
+		case c < '0' || c > '9':
+			return 0, fmt.Errorf("unexpected char %q", c)
+		}
+		if frac >= 0 {
+			frac++
+		}
+		if len(digits) == len(buf) {
+			return 0, fmt.Errorf("too many digits")
+		}
+		digits = append(digits, c)
+	}
+	if len(digits) == 0 {
+		return 0, fmt.Errorf("missing digits")
+	}
+	if frac > fracDigits {
+		return 0, fmt.Errorf("too many fractional digits: %d; max %d", frac, fracDigits)
+	}
+	if frac >= 0 {
+		// Scale the value with the explicit decimal point.
+		for ; frac < fracDigits; frac++ {
+			if len(digits) == len(buf) {
+				return 0, fmt.Errorf("too many digits")
+			}
+			digits = append(digits, '0')
+		}
+	}
+
+	n, err := strconv.ParseUint(b2s(digits), 10, 64)
+	if err != nil || !neg && n > math.MaxInt64 || neg && n > -math.MinInt64 {
+		return 0, fmt.Errorf("value out of range")
+	}
+	if neg {
+		return -int64(n), nil
+	}
+	return int64(n), nil
+}
+
+func isCurrencyCode(b []byte) bool {
+	if len(b) != 3 {
+		return false
+	}
+	for _, c := range b {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func parseMoneyAmount(s string) (units int64, nanos int32, err error) {
+	neg := false
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	intS, fracS := s, ""
+	if n := strings.IndexByte(s, '.'); n >= 0 {
+		intS, fracS = s[:n], s[n+1:]
+		if len(fracS) == 0 {
+			return 0, 0, fmt.Errorf("missing fractional part in amount")
+		}
+	}
+	if !isDigits(intS) {
+		return 0, 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if len(fracS) > 0 && !isDigits(fracS) {
+		return 0, 0, fmt.Errorf("invalid fractional part %q", fracS)
+	}
+	if len(fracS) > 9 {
+		return 0, 0, fmt.Errorf("too many fractional digits in %q; max 9 digits allowed", fracS)
+	}
+
+	units, err = strconv.ParseInt(intS, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	for i := 0; i < 9; i++ {
+		nanos *= 10
+		if i < len(fracS) {
+			nanos += int32(fracS[i] - '0')
+		}
+	}
+	if neg {
+		units, nanos = -units, -nanos
+	}
+	return units, nanos, nil
+}
+
+func isDigits(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IntN returns the next n int column values from the current row.
+//
+// nil is returned if n is negative, if the row contains less than
+// n unread columns or if any of the columns cannot be parsed.
+//
+// See SetParallelColumns for parsing wide rows in parallel.
+func (tr *Reader) IntN(n int) []int {
+	if tr.err != nil {
+		return nil
+	}
+	if !tr.checkColCount(n, "cannot read `[]int`") {
+		return nil
+	}
+	a := make([]int, n)
+	if workers := tr.parallelWorkers(n); workers > 1 {
+		if !tr.intNParallel(a, workers) {
+			return nil
+		}
+		return a
+	}
+	for i := range a {
+		a[i] = tr.Int()
+		if tr.err != nil {
+			return nil
+		}
+	}
+	return a
+}
+
+// Float64N returns the next n float64 column values from the current row.
+//
+// nil is returned if n is negative, if the row contains less than
+// n unread columns or if any of the columns cannot be parsed.
+//
+// See SetParallelColumns for parsing wide rows in parallel.
+func (tr *Reader) Float64N(n int) []float64 {
+	if tr.err != nil {
+		return nil
+	}
+	if !tr.checkColCount(n, "cannot read `[]float64`") {
+		return nil
+	}
+	a := make([]float64, n)
+	if workers := tr.parallelWorkers(n); workers > 1 {
+		if !tr.float64NParallel(a, workers) {
+			return nil
+		}
+		return a
+	}
+	for i := range a {
+		a[i] = tr.Float64()
+		if tr.err != nil {
+			return nil
+		}
+	}
+	return a
+}
+
+// Point returns the next column value from the current row as a pair of floats
+// separated by sep, e.g. `53.9,27.56` for sep=','.
+//
+// This is useful for reading coordinates stored in a single column.
+// The floats are parsed like Float64 ones, see SetDecimalSeparator.
+func (tr *Reader) Point(sep byte) (a, b float64, err error) {
+	if tr.err != nil {
+		return 0, 0, tr.err
+	}
+	col, ok := tr.nextTypedCol("point")
+	if !ok {
+		return 0, 0, tr.err
+	}
+
+	n := bytes.IndexByte(col, sep)
+	if n < 0 || bytes.IndexByte(col[n+1:], sep) >= 0 {
+		tr.setColError("cannot parse `point`", fmt.Errorf("must contain exactly two values separated by %q", sep))
+		return 0, 0, tr.err
+	}
+	a, err = tr.parseFloat(col[:n], 64, &tr.colBuf)
+	if err != nil {
+		tr.setColError("cannot parse `point`", err)
+		return 0, 0, tr.err
+	}
+	b, err = tr.parseFloat(col[n+1:], 64, &tr.colBuf)
+	if err != nil {
+		tr.setColError("cannot parse `point`", err)
+		return 0, 0, tr.err
+	}
+	return a, b, nil
+}
+
+// SetPercentAsFraction controls whether Percent returns fractions
+// instead of percents, e.g. 0.125 instead of 12.5 for `12.5%`.
+func (tr *Reader) SetPercentAsFraction(asFraction bool) {
+	tr.percentAsFraction = asFraction
+}
+
+// Percent returns the next percent column value from the current row.
+//
+// The column must be a decimal number followed by `%` with an optional
+// leading sign, e.g. `12.5%`, `+12.5%` or `-3.2%`.
+func (tr *Reader) Percent() float64 {
+	if tr.err != nil {
+		return 0
+	}
+	b, ok := tr.nextTypedCol("percent")
+	if !ok {
+		return 0
+	}
 	s := b2s(b)
 
-	f64, err := strconv.ParseFloat(s, 64)
+	if len(s) == 0 || s[len(s)-1] != '%' {
+		tr.setColError("cannot parse `percent`", fmt.Errorf("missing trailing %%"))
+		return 0
+	}
+	s = s[:len(s)-1]
+	sign := 1.0
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+	if len(s) == 0 || (s[0] != '.' && (s[0] < '0' || s[0] > '9')) {
+		tr.setColError("cannot parse `percent`", fmt.Errorf("invalid syntax"))
+		return 0
+	}
+	f, err := strconv.ParseFloat(s, 64)
 	if err != nil {
-		tr.setColError("cannot parse `float64`", err)
+		tr.setColError("cannot parse `percent`", err)
 		return 0
 	}
-	return f64
+	if tr.percentAsFraction {
+		f /= 100
+	}
+	return sign * f
+}
+
+// Bit returns bit n of the next integer column value from the current row.
+//
+// This is useful for reading flags packed into integer bitmasks.
+// Bits are numbered from the least significant bit starting from 0.
+// Negative values are treated as two's complement.
+func (tr *Reader) Bit(n uint) bool {
+	if tr.err != nil {
+		return false
+	}
+	b, ok := tr.nextTypedCol("bit")
+	if !ok {
+		return false
+	}
+	if n >= 64 {
+		tr.setColError("cannot read `bit`", fmt.Errorf("bit number %d is out of range [0..63]", n))
+		return false
+	}
+	s := b2s(b)
+
+	// Fast path - attempt to use Atoi
+	x, err := strconv.Atoi(s)
+	if err == nil {
+		return uint64(x)&(1<<n) != 0
+	}
+
+	// Slow path - use ParseUint
+	x64, err := strconv.ParseUint(s, tr.uintBase(s), 64)
+	if err != nil {
+		tr.setColError("cannot parse `bit`", err)
+		return false
+	}
+	return x64&(1<<n) != 0
 }
@@ -0,0 +1,351 @@
+package dsvreader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// SetKeepTrailingEmptyLine controls whether Lines returns the empty line
+// following the trailing newline in a column.
+//
+// By default the trailing empty line is dropped, so `a\nb\n` results
+// in two lines.
+func (tr *Reader) SetKeepTrailingEmptyLine(keep bool) {
+	tr.keepTrailingEmpty = keep
+}
+
+// Lines returns the next column value from the current row split into lines.
+//
+// The column is unescaped the same way as Bytes does, then split on newlines.
+//
+// The returned value is valid until the next call to Reader.
+func (tr *Reader) Lines() [][]byte {
+	b := tr.Bytes()
+	if tr.err != nil {
+		return nil
+	}
+
+	lines := tr.lines[:0]
+	for {
+		n := bytes.IndexByte(b, '\n')
+		if n < 0 {
+			break
+		}
+		lines = append(lines, b[:n])
+		b = b[n+1:]
+	}
+	if len(b) > 0 || tr.keepTrailingEmpty {
+		lines = append(lines, b)
+	}
+	tr.lines = lines
+	return lines
+}
+
+// WriteColumnTo writes the next column value from the current row to w.
+//
+// The column is unescaped the same way as Bytes does. This avoids
+// materializing big columns as strings when streaming them to files
+// or hashes.
+//
+// The column is consumed even if writing to w fails. Write errors
+// are returned without being set as the reader error, so the reader
+// may proceed with the next column.
+func (tr *Reader) WriteColumnTo(w io.Writer) (int64, error) {
+	b := tr.Bytes()
+	if tr.err != nil {
+		return 0, tr.err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// StringN returns the next n string column values from the current row.
+//
+// nil is returned if n is negative or if the row contains less than
+// n unread columns.
+func (tr *Reader) StringN(n int) []string {
+	if tr.err != nil {
+		return nil
+	}
+	if !tr.checkColCount(n, "cannot read `[]string`") {
+		return nil
+	}
+	a := make([]string, n)
+	for i := range a {
+		a[i] = tr.String()
+		if tr.err != nil {
+			return nil
+		}
+	}
+	return a
+}
+
+// checkColCount sets the column error with the given msg if n is negative.
+func (tr *Reader) checkColCount(n int, msg string) bool {
+	if n < 0 {
+		tr.setColError(msg, fmt.Errorf("negative number of columns: %d", n))
+		return false
+	}
+	return true
+}
+
+// RemainingCols returns the remaining string column values from the current row.
+//
+// HasCols returns false after the call. nil is returned on error.
+func (tr *Reader) RemainingCols() []string {
+	if tr.err != nil {
+		return nil
+	}
+	var a []string
+	for tr.HasCols() {
+		s := tr.String()
+		if tr.err != nil {
+			return nil
+		}
+		a = append(a, s)
+	}
+	return a
+}
+
+// RemainingBytes returns the remaining bytes column values from the current row.
+//
+// HasCols returns false after the call. nil is returned on error.
+//
+// The returned value is valid until the next call to Reader.
+func (tr *Reader) RemainingBytes() [][]byte {
+	if tr.err != nil {
+		return nil
+	}
+	a := tr.remaining[:0]
+	for tr.HasCols() {
+		b := tr.Bytes()
+		if tr.err != nil {
+			return nil
+		}
+		a = append(a, b)
+	}
+	tr.remaining = a
+	return a
+}
+
+// ReadAll reads all the remaining rows and returns their string column values.
+//
+// This is a convenience method for small inputs, which allocates memory
+// for every column. Use Next with column readers for processing
+// big inputs. The rows read before an error are returned together
+// with the error. The error is nil at the end of data.
+func (tr *Reader) ReadAll() ([][]string, error) {
+	var rows [][]string
+	for tr.Next() {
+		a := tr.RemainingCols()
+		if tr.err != nil {
+			break
+		}
+		rows = append(rows, a)
+	}
+	return rows, tr.Error()
+}
+
+// JSON reads the next JSON column value from the current row into dst
+// with json.Unmarshal.
+//
+// The column is unescaped the same way as Bytes does before decoding.
+// Decoding errors are set as column errors and returned.
+func (tr *Reader) JSON(dst interface{}) error {
+	b := tr.Bytes()
+	if tr.err != nil {
+		return tr.err
+	}
+	if err := json.Unmarshal(b, dst); err != nil {
+		tr.setColError("cannot parse `json`", err)
+		return tr.err
+	}
+	return nil
+}
+
+// SetRuneLenient controls whether Rune reads the first rune of columns
+// containing multiple runes instead of reporting an error.
+func (tr *Reader) SetRuneLenient(lenient bool) {
+	tr.runeLenient = lenient
+}
+
+// Rune returns the next single-rune column value from the current row.
+//
+// The column is unescaped the same way as Bytes does. It must contain
+// exactly one valid UTF-8 encoded rune unless SetRuneLenient is enabled.
+func (tr *Reader) Rune() rune {
+	b := tr.Bytes()
+	if tr.err != nil {
+		return 0
+	}
+	if len(b) == 0 {
+		tr.setColError("cannot parse `rune`", fmt.Errorf("empty value"))
+		return 0
+	}
+	r, n := utf8.DecodeRune(b)
+	if r == utf8.RuneError && n == 1 {
+		tr.setColError("cannot parse `rune`", fmt.Errorf("invalid UTF-8 encoding in %q", b))
+		return 0
+	}
+	if n < len(b) && !tr.runeLenient {
+		tr.setColError("cannot parse `rune`", fmt.Errorf("%q contains more than one rune", b))
+		return 0
+	}
+	return r
+}
+
+// SetStringMaxStrict controls whether StringMax results in an error
+// for columns exceeding the maximum length instead of truncating them.
+func (tr *Reader) SetStringMaxStrict(strict bool) {
+	tr.stringMaxStrict = strict
+}
+
+// StringMax returns the next string column value from the current row
+// limited to max bytes.
+//
+// Longer values are truncated to at most max bytes without splitting
+// UTF-8 encoded runes. The length is checked after unescaping,
+// i.e. on the bytes String would return.
+//
+// Call SetStringMaxStrict for reporting an error on longer values instead.
+// Negative max results in an error.
+func (tr *Reader) StringMax(max int) string {
+	b := tr.Bytes()
+	if tr.err != nil {
+		return ""
+	}
+	if max < 0 {
+		tr.setColError("cannot read `string`", fmt.Errorf("negative max length %d", max))
+		return ""
+	}
+	if len(b) <= max {
+		return string(b)
+	}
+	if tr.stringMaxStrict {
+		tr.setColError("cannot read `string`", fmt.Errorf("length %d exceeds max length %d", len(b), max))
+		return ""
+	}
+
+	n := max
+	for n > 0 && n < len(b) && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	return string(b[:n])
+}
+
+// SetEnumCaseInsensitive controls whether Enum matches allowed values
+// case-insensitively.
+func (tr *Reader) SetEnumCaseInsensitive(caseInsensitive bool) {
+	tr.enumFold = caseInsensitive
+}
+
+// Enum returns the next column value from the current row, which must be
+// one of the allowed values.
+//
+// The matching allowed value is returned, so it is safe to retain
+// and no memory is allocated. Other values result in an error.
+// Use EnumIn for big sets of allowed values.
+func (tr *Reader) Enum(allowed ...string) string {
+	b := tr.Bytes()
+	if tr.err != nil {
+		return ""
+	}
+	for _, v := range allowed {
+		if v == b2s(b) || tr.enumFold && strings.EqualFold(v, b2s(b)) {
+			return v
+		}
+	}
+	tr.setColError("cannot parse `enum`", fmt.Errorf("unexpected value %q; allowed values: %q", b, allowed))
+	return ""
+}
+
+// EnumSet is a set of allowed values for EnumIn.
+//
+// It is safe to share the set between readers.
+type EnumSet struct {
+	values map[string]string
+	fold   bool
+}
+
+// NewEnumSet returns a set of allowed values for EnumIn.
+//
+// Values are matched case-insensitively if caseInsensitive is set.
+func NewEnumSet(caseInsensitive bool, values ...string) *EnumSet {
+	es := &EnumSet{
+		values: make(map[string]string, len(values)),
+		fold:   caseInsensitive,
+	}
+	for _, v := range values {
+		k := v
+		if caseInsensitive {
+			k = strings.ToLower(v)
+		}
+		if _, ok := es.values[k]; !ok {
+			es.values[k] = v
+		}
+	}
+	return es
+}
+
+// EnumIn returns the next column value from the current row, which must be
+// in the given set of allowed values.
+//
+// Like Enum, it returns the matching allowed value. Values are looked up
+// in a map, so EnumIn is faster than Enum for big sets.
+func (tr *Reader) EnumIn(set *EnumSet) string {
+	b := tr.Bytes()
+	if tr.err != nil {
+		return ""
+	}
+	v, ok := set.values[string(b)]
+	if !ok && set.fold {
+		v, ok = set.values[tr.lowerString(b)]
+	}
+	if !ok {
+		tr.setColError("cannot parse `enum`", fmt.Errorf("unexpected value %q", b))
+		return ""
+	}
+	return v
+}
+
+// lowerString returns b converted to lower case.
+//
+// The returned string is valid until the next call to Reader
+// for ASCII b.
+func (tr *Reader) lowerString(b []byte) string {
+	buf := tr.colBuffer(len(b))
+	for i, c := range b {
+		if c >= utf8.RuneSelf {
+			return strings.ToLower(string(b))
+		}
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		buf[i] = c
+	}
+	return b2s(buf)
+}
+
+// EnumOr returns the index of the next column value from the current row
+// in values.
+//
+// dflt is returned if the value is missing in values. EnumOr never sets
+// an error for unknown values, so new values may appear in the data
+// without breaking the reader. Errors are set only if the column
+// cannot be read. dflt is returned in this case too.
+func (tr *Reader) EnumOr(values []string, dflt int) int {
+	b := tr.Bytes()
+	if tr.err != nil {
+		return dflt
+	}
+	for i, v := range values {
+		if v == b2s(b) {
+			return i
+		}
+	}
+	return dflt
+}
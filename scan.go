@@ -0,0 +1,175 @@
+package dsvreader
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// Scan reads the current row into the struct pointed to by dst.
+//
+// Struct fields are mapped to columns via `dsv` tags containing
+// either the zero-based column index or the column name, e.g.:
+//
+//	type Order struct {
+//		ID     int       `dsv:"0"`
+//		Amount float64   `dsv:"amount"`
+//		Date   time.Time `dsv:"date"`
+//		Note   *string   `dsv:"note"`
+//	}
+//
+// Column names are set either by reading the header or via SetColumnNames.
+// Untagged and unexported fields, as well as fields tagged with `dsv:"-"`,
+// are skipped.
+//
+// The column reader is chosen by the field type: strings, []byte, bools,
+// integers and floats are read with the corresponding readers,
+// time.Time with DateTime and time.Duration with Duration. Types derived
+// from them are supported too. Pointer fields are set to nil for NULL
+// values, see SetNullToken.
+//
+// The row is consumed, so Next may be called after Scan.
+func (tr *Reader) Scan(dst interface{}) error {
+	if tr.err != nil {
+		return tr.err
+	}
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		tr.setColError("cannot scan row", fmt.Errorf("dst must be a non-nil pointer to struct; got %T", dst))
+		return tr.err
+	}
+	if tr.row == 0 {
+		tr.setColError("cannot scan row", ErrMissingNext)
+		return tr.err
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	cols := -1
+	if tr.missingValue == nil {
+		cols = tr.countCols(tr.unmutatedRow())
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("dsv")
+		if !ok || tag == "-" || !f.IsExported() {
+			continue
+		}
+		idx, err := strconv.Atoi(tag)
+		if err != nil {
+			idx, ok = tr.ColIndex(tag)
+			if !ok {
+				tr.setColError("cannot scan row", fmt.Errorf("unknown column %q for field %s", tag, f.Name))
+				return tr.err
+			}
+		}
+		if idx < 0 || cols >= 0 && idx >= cols {
+			tr.setColError("cannot scan row", fmt.Errorf("column index %d for field %s is out of range for the row with %d columns", idx, f.Name, cols))
+			return tr.err
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() != reflect.Ptr {
+			tr.readAt(idx, func() {
+				tr.scanValue(fv)
+			})
+			if tr.err != nil {
+				return tr.err
+			}
+			continue
+		}
+
+		null := false
+		tr.readAt(idx, func() {
+			null = tr.nextNull()
+		})
+		if tr.err != nil {
+			return tr.err
+		}
+		if null {
+			fv.Set(reflect.Zero(fv.Type()))
+			continue
+		}
+		p := reflect.New(fv.Type().Elem())
+		tr.readAt(idx, func() {
+			tr.scanValue(p.Elem())
+		})
+		if tr.err != nil {
+			return tr.err
+		}
+		fv.Set(p)
+	}
+
+	// Consume the row.
+	tr.b = nil
+	return nil
+}
+
+// nextNull reads the next column and returns true if it is NULL.
+func (tr *Reader) nextNull() bool {
+	b, err := tr.nextCol()
+	if err != nil {
+		tr.setColError("cannot read column", err)
+		return false
+	}
+	return tr.isNull(b)
+}
+
+// scanValue reads the next column into fv with the reader matching its type.
+func (tr *Reader) scanValue(fv reflect.Value) {
+	switch fv.Type() {
+	case timeType:
+		fv.Set(reflect.ValueOf(tr.DateTime()))
+		return
+	case durationType:
+		fv.SetInt(int64(tr.Duration()))
+		return
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(tr.String())
+	case reflect.Bool:
+		fv.SetBool(tr.Bool())
+	case reflect.Int:
+		fv.SetInt(int64(tr.Int()))
+	case reflect.Int8:
+		fv.SetInt(int64(tr.Int8()))
+	case reflect.Int16:
+		fv.SetInt(int64(tr.Int16()))
+	case reflect.Int32:
+		fv.SetInt(int64(tr.Int32()))
+	case reflect.Int64:
+		fv.SetInt(tr.Int64())
+	case reflect.Uint:
+		fv.SetUint(uint64(tr.Uint()))
+	case reflect.Uint8:
+		fv.SetUint(uint64(tr.Uint8()))
+	case reflect.Uint16:
+		fv.SetUint(uint64(tr.Uint16()))
+	case reflect.Uint32:
+		fv.SetUint(uint64(tr.Uint32()))
+	case reflect.Uint64:
+		fv.SetUint(tr.Uint64())
+	case reflect.Float32:
+		fv.SetFloat(float64(tr.Float32()))
+	case reflect.Float64:
+		fv.SetFloat(tr.Float64())
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			// Copy the bytes, since they are valid until the next call to Reader.
+			fv.SetBytes(append([]byte{}, tr.Bytes()...))
+			return
+		}
+		fallthrough
+	default:
+		tr.col++
+		tr.setColError("cannot scan column", fmt.Errorf("unsupported field type %s", fv.Type()))
+	}
+}
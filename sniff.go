@@ -0,0 +1,91 @@
+package dsvreader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// sniffSeparators contains separators detected by SniffSeparator
+// in the order of preference.
+var sniffSeparators = []byte{',', '\t', '|', ';'}
+
+const (
+	sniffMaxLines = 10
+	sniffMaxBytes = 64 << 10
+)
+
+// SniffSeparator detects the column separator of delimiter-separated
+// data read from r.
+//
+// The first lines of the data are read and the separator is chosen among
+// `,`, `\t`, `|` and `;`. The separator occurring the same number of times
+// in every line wins. If there are many such separators, the most frequent
+// one wins. If there are none, the separator most frequent in the first line
+// is chosen. `,` is returned for single-column data.
+//
+// The returned reader replays the bytes consumed from r, so it must be used
+// instead of r:
+//
+//	sep, r, err := dsvreader.SniffSeparator(r)
+//	if err != nil {
+//		return err
+//	}
+//	tr := dsvreader.NewCustom(sep, r)
+func SniffSeparator(r io.Reader) (byte, io.Reader, error) {
+	var sample []byte
+	buf := make([]byte, 4<<10)
+	for bytes.Count(sample, []byte{'\n'}) < sniffMaxLines && len(sample) < sniffMaxBytes {
+		n, err := r.Read(buf)
+		sample = append(sample, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("cannot read data: %w", err)
+		}
+	}
+	return sniffSeparator(sample), io.MultiReader(bytes.NewReader(sample), r), nil
+}
+
+func sniffSeparator(sample []byte) byte {
+	lines := bytes.Split(sample, []byte{'\n'})
+	if len(lines) > 1 {
+		// The last line is either empty or incomplete.
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > sniffMaxLines {
+		lines = lines[:sniffMaxLines]
+	}
+
+	var best byte
+	bestCount := 0
+	for _, sep := range sniffSeparators {
+		n := bytes.Count(lines[0], []byte{sep})
+		if n == 0 {
+			continue
+		}
+		consistent := true
+		for _, line := range lines[1:] {
+			if bytes.Count(line, []byte{sep}) != n {
+				consistent = false
+				break
+			}
+		}
+		if consistent && n > bestCount {
+			best, bestCount = sep, n
+		}
+	}
+	if best != 0 {
+		return best
+	}
+
+	best = ','
+	bestCount = 0
+	for _, sep := range sniffSeparators {
+		if n := bytes.Count(lines[0], []byte{sep}); n > bestCount {
+			best, bestCount = sep, n
+		}
+	}
+	return best
+}
@@ -0,0 +1,169 @@
+package dsvreader
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// NewCSVWriter returns new Writer that writes CSV data to w.
+func NewCSVWriter(w io.Writer) *Writer {
+	return NewCustomWriter(',', w)
+}
+
+// NewTSVWriter returns new Writer that writes TSV data to w.
+func NewTSVWriter(w io.Writer) *Writer {
+	return NewCustomWriter('\t', w)
+}
+
+// NewPSVWriter returns new Writer that writes PSV data to w.
+func NewPSVWriter(w io.Writer) *Writer {
+	return NewCustomWriter('|', w)
+}
+
+// NewCustomWriter returns new Writer that writes arbitrary
+// delimiter-separated data to w.
+func NewCustomWriter(sep byte, w io.Writer) *Writer {
+	return &Writer{
+		w:   bufio.NewWriter(w),
+		sep: sep,
+		row: 1,
+	}
+}
+
+// Writer writes delimiter-separated data readable by Reader.
+//
+// Columns are escaped the same way ClickHouse does, so Reader with
+// the default settings reads them back unchanged: backslashes, newlines,
+// tabs and other control chars written as `\\`, `\n`, `\t`, `\r`,
+// `\b`, `\f` and `\0`.
+//
+// Writer is buffered, so call Flush after writing all the rows.
+// Write methods don't return errors. The first error is kept
+// and returned by Flush and Error, while subsequent writes are ignored.
+type Writer struct {
+	w   *bufio.Writer
+	sep byte
+	buf []byte
+
+	row int
+	col int
+	err error
+}
+
+// WriteBytes writes b as the next column of the current row.
+//
+// Columns containing the separator cannot be written unless
+// the separator is escaped, e.g. the tab for TSV.
+func (w *Writer) WriteBytes(b []byte) {
+	if w.err != nil {
+		return
+	}
+	w.col++
+	if w.col > 1 {
+		w.w.WriteByte(w.sep)
+	}
+
+	start := 0
+	for i, c := range b {
+		esc := escapeByte(c)
+		if esc == 0 {
+			if c == w.sep {
+				w.err = fmt.Errorf("cannot write column at row #%d, col #%d: value %q contains the separator %q", w.row, w.col, b, w.sep)
+				return
+			}
+			continue
+		}
+		w.w.Write(b[start:i])
+		w.w.WriteByte('\\')
+		w.w.WriteByte(esc)
+		start = i + 1
+	}
+	w.w.Write(b[start:])
+}
+
+// escapeByte returns the char c is escaped with or 0 if c isn't escaped.
+//
+// This is the inverse of unescapeByte.
+func escapeByte(c byte) byte {
+	switch c {
+	case '\\':
+		return '\\'
+	case '\b':
+		return 'b'
+	case '\f':
+		return 'f'
+	case '\r':
+		return 'r'
+	case '\n':
+		return 'n'
+	case '\t':
+		return 't'
+	case 0:
+		return '0'
+	default:
+		return 0
+	}
+}
+
+// WriteString writes s as the next column of the current row.
+func (w *Writer) WriteString(s string) {
+	w.buf = append(w.buf[:0], s...)
+	w.WriteBytes(w.buf)
+}
+
+// WriteInt writes n as the next column of the current row.
+func (w *Writer) WriteInt(n int) {
+	w.WriteInt64(int64(n))
+}
+
+// WriteInt64 writes n as the next column of the current row.
+func (w *Writer) WriteInt64(n int64) {
+	w.buf = strconv.AppendInt(w.buf[:0], n, 10)
+	w.WriteBytes(w.buf)
+}
+
+// WriteUint64 writes n as the next column of the current row.
+func (w *Writer) WriteUint64(n uint64) {
+	w.buf = strconv.AppendUint(w.buf[:0], n, 10)
+	w.WriteBytes(w.buf)
+}
+
+// WriteFloat64 writes f as the next column of the current row
+// in the shortest form read back by Float64 as the same value.
+func (w *Writer) WriteFloat64(f float64) {
+	w.buf = strconv.AppendFloat(w.buf[:0], f, 'g', -1, 64)
+	w.WriteBytes(w.buf)
+}
+
+// EndRow terminates the current row.
+func (w *Writer) EndRow() {
+	if w.err != nil {
+		return
+	}
+	w.w.WriteByte('\n')
+	w.row++
+	w.col = 0
+}
+
+// Flush writes the buffered data to the underlying writer.
+//
+// It returns the first error occurred while writing.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.w.Flush(); err != nil {
+		w.err = fmt.Errorf("cannot write row #%d: %w", w.row, err)
+	}
+	return w.err
+}
+
+// Error returns the first error occurred while writing.
+//
+// Errors from the underlying writer may be reported only by Flush,
+// since the data is buffered.
+func (w *Writer) Error() error {
+	return w.err
+}